@@ -0,0 +1,143 @@
+package graphql
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// AppSyncAuth authorizes the realtime connections and subscriptions of AWS AppSync APIs.
+type AppSyncAuth interface {
+	// Headers returns the authorization headers of a request of payload to the GraphQL endpoint apiURL.
+	Headers(ctx context.Context, apiURL string, payload []byte) (map[string]string, error)
+}
+
+// AppSyncAPIKey authorizes with an API key.
+type AppSyncAPIKey string
+
+// Headers returns the host and the x-api-key headers.
+func (k AppSyncAPIKey) Headers(ctx context.Context, apiURL string, payload []byte) (map[string]string, error) {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"host": u.Host, "x-api-key": string(k)}, nil
+}
+
+// RequestModifier returns a request modifier that sets the x-api-key header of the requests of a client.
+func (k AppSyncAPIKey) RequestModifier() RequestModifier {
+	return func(req *http.Request) {
+		req.Header.Set("x-api-key", string(k))
+	}
+}
+
+// AppSyncToken authorizes with the tokens of a provider, e.g. the JWTs of Cognito user pools or OpenID Connect.
+type AppSyncToken struct {
+	Provider TokenProvider
+}
+
+// Headers returns the host and the Authorization headers. The token is sent as is, without its type.
+func (t AppSyncToken) Headers(ctx context.Context, apiURL string, payload []byte) (map[string]string, error) {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return nil, err
+	}
+	token, err := t.Provider.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("problem getting token: %w", err)
+	}
+	return map[string]string{"host": u.Host, "Authorization": token.AccessToken}, nil
+}
+
+// AppSync configures the AWS AppSync realtime protocol of a subscription client.
+type AppSync struct {
+	// APIURL is the GraphQL endpoint of the API, whose host authorizes the requests. If empty, it's derived from
+	// the realtime URL of the client, e.g. https://example.appsync-api.us-east-1.amazonaws.com/graphql
+	// for wss://example.appsync-realtime-api.us-east-1.amazonaws.com/graphql.
+	APIURL string
+	// Auth authorizes the connection and the subscriptions.
+	Auth AppSyncAuth
+}
+
+// WithAppSync sets the client to use the AWS AppSync realtime protocol: the connection is authorized by the header
+// and payload query params of its URL, and each subscription by the authorization extension of its start message.
+// The URL of the client is the realtime endpoint of the API
+func (sc *SubscriptionClient) WithAppSync(appSync AppSync) *SubscriptionClient {
+	sc.appSync = &appSync
+	return sc
+}
+
+// apiURL returns the GraphQL endpoint of the API of the realtime URL.
+func (a *AppSync) apiURL(realtimeURL string) string {
+	if a.APIURL != "" {
+		return a.APIURL
+	}
+	u, err := url.Parse(realtimeURL)
+	if err != nil {
+		return realtimeURL
+	}
+	switch u.Scheme {
+	case "wss":
+		u.Scheme = "https"
+	case "ws":
+		u.Scheme = "http"
+	}
+	u.Host = strings.Replace(u.Host, "appsync-realtime-api", "appsync-api", 1)
+	// Custom domains serve the realtime endpoint at /graphql/realtime.
+	u.Path = strings.TrimSuffix(u.Path, "/realtime")
+	u.RawQuery = ""
+	return u.String()
+}
+
+// connectionURL returns the realtime URL with the authorization of the connection.
+func (a *AppSync) connectionURL(ctx context.Context, realtimeURL string) (string, error) {
+	headers, err := a.Auth.Headers(ctx, a.apiURL(realtimeURL)+"/connect", []byte("{}"))
+	if err != nil {
+		return "", err
+	}
+	header, err := json.Marshal(headers)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(realtimeURL)
+	if err != nil {
+		return "", err
+	}
+	params := u.Query()
+	params.Set("header", base64.StdEncoding.EncodeToString(header))
+	params.Set("payload", base64.StdEncoding.EncodeToString([]byte("{}")))
+	u.RawQuery = params.Encode()
+	return u.String(), nil
+}
+
+// startPayload returns the payload of the start message of a subscription.
+func (a *AppSync) startPayload(ctx context.Context, realtimeURL string, sub *subscription) ([]byte, error) {
+	data, err := json.Marshal(struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables,omitempty"`
+	}{
+		Query:     sub.query,
+		Variables: sub.variables,
+	})
+	if err != nil {
+		return nil, err
+	}
+	authorization, err := a.Auth.Headers(ctx, a.apiURL(realtimeURL), data)
+	if err != nil {
+		return nil, err
+	}
+	type extensions struct {
+		Authorization map[string]string `json:"authorization"`
+	}
+	return json.Marshal(struct {
+		Data       string     `json:"data"`
+		Extensions extensions `json:"extensions"`
+	}{
+		Data:       string(data),
+		Extensions: extensions{Authorization: authorization},
+	})
+}
@@ -0,0 +1,81 @@
+package graphql
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestAppSync_connectionURL(t *testing.T) {
+	tests := []struct {
+		realtimeURL string
+		wantHost    string
+	}{
+		{realtimeURL: "wss://example.appsync-realtime-api.us-east-1.amazonaws.com/graphql", wantHost: "example.appsync-api.us-east-1.amazonaws.com"},
+		{realtimeURL: "wss://api.example.com/graphql/realtime", wantHost: "api.example.com"},
+	}
+	for _, tc := range tests {
+		appSync := &AppSync{Auth: AppSyncAPIKey("da2-key")}
+		got, err := appSync.connectionURL(context.Background(), tc.realtimeURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := url.Parse(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		header, err := base64.StdEncoding.DecodeString(u.Query().Get("header"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var headers map[string]string
+		if err := json.Unmarshal(header, &headers); err != nil {
+			t.Fatal(err)
+		}
+		if want := map[string]string{"host": tc.wantHost, "x-api-key": "da2-key"}; !reflect.DeepEqual(headers, want) {
+			t.Errorf("%s: got header %v, want %v", tc.realtimeURL, headers, want)
+		}
+		if got := u.Query().Get("payload"); got != "e30=" {
+			t.Errorf("%s: got payload %q, want e30=", tc.realtimeURL, got)
+		}
+	}
+}
+
+func TestSubscriptionClient_WithAppSync(t *testing.T) {
+	conn := &tokenTestConn{}
+	sc := NewSubscriptionClient("wss://example.appsync-realtime-api.us-east-1.amazonaws.com/graphql").
+		WithAppSync(AppSync{Auth: AppSyncAPIKey("da2-key")}).
+		WithWebSocket(func(sc *SubscriptionClient) (WebsocketConn, error) {
+			return conn, nil
+		})
+	if err := sc.init(); err != nil {
+		t.Fatal(err)
+	}
+
+	sub := &subscription{query: "subscription{onCreateTodo{id}}", variables: map[string]interface{}{"owner": "gopher"}}
+	if err := sc.startSubscription("1", sub); err != nil {
+		t.Fatal(err)
+	}
+	msg := conn.messages[len(conn.messages)-1]
+	if msg.Type != GQL_START || msg.ID != "1" {
+		t.Errorf("got message %v, want a start message", msg)
+	}
+	var payload struct {
+		Data       string
+		Extensions struct {
+			Authorization map[string]string
+		}
+	}
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"query":"subscription{onCreateTodo{id}}","variables":{"owner":"gopher"}}`; payload.Data != want {
+		t.Errorf("got data %s, want %s", payload.Data, want)
+	}
+	if want := map[string]string{"host": "example.appsync-api.us-east-1.amazonaws.com", "x-api-key": "da2-key"}; !reflect.DeepEqual(payload.Extensions.Authorization, want) {
+		t.Errorf("got authorization %v, want %v", payload.Extensions.Authorization, want)
+	}
+}
@@ -0,0 +1,222 @@
+package graphql
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BatchConfig configures the batching of queries.
+type BatchConfig struct {
+	// Window is the time to wait for other queries after the first query of a batch, 5ms if zero.
+	Window time.Duration
+	// MaxSize is the maximum number of queries of a batch, which is sent as soon as it's full.
+	// Zero means no limit.
+	MaxSize int
+}
+
+// WithBatching returns a copy of the client that collects the queries issued within a time window,
+// and sends them as one batched HTTP request, i.e. a JSON array of requests, to which the server
+// replies with a JSON array of responses in the same order. Each caller gets the response to its query,
+// as if it was sent alone.
+//
+// Mutations are sent alone, since servers may run the operations of a batch concurrently.
+// Callers stop waiting when their context is done, and their query is left out of the batch
+// if it wasn't sent yet. Batches are sent with the values of the context of their first caller,
+// and are canceled once every caller stopped waiting.
+func (c *Client) WithBatching(config BatchConfig) *Client {
+	if config.Window <= 0 {
+		config.Window = 5 * time.Millisecond
+	}
+	nc := *c
+	nc.batcher = &batcher{config: config, batches: make(map[*Client]*batch)}
+	return &nc
+}
+
+// batcher collects the queries of clients into batches.
+type batcher struct {
+	config BatchConfig
+	mu     sync.Mutex
+	// batches holds the batch being collected for each client, since copies
+	// of a client share the batcher but may send requests differently.
+	batches map[*Client]*batch
+}
+
+type batch struct {
+	items []*batchItem
+	timer *time.Timer
+	// sent is set once the batch is being sent, and waiters is the number of callers
+	// waiting for its responses. They are guarded by the mutex of the batcher.
+	sent    bool
+	waiters int
+	// ctx is the context of the request of the batch, canceled by cancel when no caller waits anymore.
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// batchItem is an operation of a batch, and its response once done is closed.
+type batchItem struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+
+	done    chan struct{}
+	data    []byte
+	resp    *http.Response
+	respBuf io.Reader
+	errs    Errors
+}
+
+// batch adds the operation to the batch of the client, and waits for its response.
+func (c *Client) batch(ctx context.Context, query string, variables map[string]interface{}) ([]byte, *http.Response, io.Reader, Errors) {
+	b := c.batcher
+	item := &batchItem{Query: query, Variables: variables, done: make(chan struct{})}
+	b.mu.Lock()
+	current, ok := b.batches[c]
+	if !ok {
+		current = &batch{}
+		current.ctx, current.cancel = context.WithCancel(detachedContext{ctx})
+		b.batches[c] = current
+		current.timer = time.AfterFunc(b.config.Window, func() { c.flushBatch(current) })
+	}
+	current.items = append(current.items, item)
+	current.waiters++
+	full := b.config.MaxSize > 0 && len(current.items) >= b.config.MaxSize
+	b.mu.Unlock()
+	if full {
+		go c.flushBatch(current)
+	}
+
+	select {
+	case <-item.done:
+		return item.data, item.resp, item.respBuf, item.errs
+	case <-ctx.Done():
+		c.leaveBatch(current, item)
+		return nil, nil, nil, Errors{newError(ErrRequestError, ctx.Err())}
+	}
+}
+
+// leaveBatch removes the item from the batch if it wasn't sent yet, and cancels the batch
+// once no caller waits for it.
+func (c *Client) leaveBatch(current *batch, item *batchItem) {
+	b := c.batcher
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !current.sent {
+		for i, it := range current.items {
+			if it == item {
+				current.items = append(current.items[:i:i], current.items[i+1:]...)
+				break
+			}
+		}
+	}
+	current.waiters--
+	if current.waiters > 0 {
+		return
+	}
+	if !current.sent && b.batches[c] == current {
+		delete(b.batches, c)
+		current.timer.Stop()
+	}
+	current.cancel()
+}
+
+// flushBatch sends the batch, unless it was already sent.
+func (c *Client) flushBatch(current *batch) {
+	b := c.batcher
+	b.mu.Lock()
+	if b.batches[c] != current {
+		b.mu.Unlock()
+		return
+	}
+	delete(b.batches, c)
+	current.timer.Stop()
+	current.sent = true
+	items := current.items
+	b.mu.Unlock()
+	defer current.cancel()
+
+	responses, resp, errs := c.sendBatch(current.ctx, items)
+	for i, item := range items {
+		item.resp = resp
+		if len(errs) > 0 {
+			item.errs = append(Errors(nil), errs...)
+		} else {
+			r := responses[i]
+			if r.Data != nil && len(*r.Data) > 0 && string(*r.Data) != "null" {
+				item.data = []byte(*r.Data)
+			}
+			item.errs = r.Errors
+			if c.debug {
+				raw, _ := json.Marshal(r)
+				item.respBuf = bytes.NewReader(raw)
+			}
+		}
+		close(item.done)
+	}
+}
+
+type batchResponse struct {
+	Data   *json.RawMessage `json:"data"`
+	Errors Errors           `json:"errors,omitempty"`
+}
+
+// sendBatch sends the items as one request, and returns a response for each item,
+// or the errors of the request.
+func (c *Client) sendBatch(ctx context.Context, items []*batchItem) ([]batchResponse, *http.Response, Errors) {
+	body, err := json.Marshal(items)
+	if err != nil {
+		return nil, nil, Errors{newError(ErrGraphQLEncode, err)}
+	}
+	request, err := http.NewRequestWithContext(withIdempotent(c.withHedgeable(ctx)), http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, Errors{newError(ErrRequestError, fmt.Errorf("problem constructing request: %w", err))}
+	}
+	request.Header.Add("Content-Type", "application/json")
+	if c.requestModifier != nil {
+		c.requestModifier(request)
+	}
+
+	resp, err := c.authorizedRoundTrip(request)
+	if err != nil {
+		e := requestError(err)
+		if c.debug {
+			e = e.withRequest(request, bytes.NewReader(body))
+		}
+		return nil, nil, Errors{e}
+	}
+	defer resp.Body.Close()
+
+	r := io.Reader(resp.Body)
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, resp, Errors{newError(ErrJsonDecode, fmt.Errorf("problem trying to create gzip reader: %w", err))}
+		}
+		defer gr.Close()
+		r = gr
+	}
+	if resp.StatusCode != http.StatusOK {
+		b, _ := ioutil.ReadAll(r)
+		e := newError(ErrRequestError, fmt.Errorf("%v; body: %q", resp.Status, b))
+		if c.debug {
+			e = e.withRequest(request, bytes.NewReader(body))
+		}
+		return nil, resp, Errors{e}
+	}
+
+	var responses []batchResponse
+	if err := json.NewDecoder(r).Decode(&responses); err != nil {
+		return nil, resp, Errors{newError(ErrJsonDecode, err)}
+	}
+	if len(responses) != len(items) {
+		return nil, resp, Errors{newError(ErrJsonDecode, fmt.Errorf("got %d responses to a batch of %d operations", len(responses), len(items)))}
+	}
+	return responses, resp, nil
+}
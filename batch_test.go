@@ -0,0 +1,165 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zainirfan13/graphql-client"
+)
+
+func TestClient_WithBatching(t *testing.T) {
+	var requests, batched int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		body := mustRead(req.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if body[0] != '[' {
+			mustWrite(w, `{"data": {"addStar": {"id": "1"}}}`)
+			return
+		}
+		var in []struct {
+			Query     string
+			Variables struct{ Login string }
+		}
+		if err := json.Unmarshal([]byte(body), &in); err != nil {
+			t.Error(err)
+		}
+		atomic.AddInt32(&batched, int32(len(in)))
+		var out []interface{}
+		for _, op := range in {
+			if op.Variables.Login == "unknown" {
+				out = append(out, map[string]interface{}{"data": nil, "errors": []interface{}{map[string]interface{}{"message": "not found"}}})
+				continue
+			}
+			out = append(out, map[string]interface{}{"data": map[string]interface{}{"user": map[string]interface{}{"name": "Name of " + op.Variables.Login}}})
+		}
+		b, _ := json.Marshal(out)
+		mustWrite(w, string(b))
+	})
+
+	query := func(client *graphql.Client, login string) (string, error) {
+		var q struct {
+			User struct {
+				Name string
+			} `graphql:"user(login: $login)"`
+		}
+		err := client.Query(context.Background(), &q, map[string]interface{}{"login": login})
+		return q.User.Name, err
+	}
+
+	tests := []struct {
+		config       graphql.BatchConfig
+		wantRequests int32
+	}{
+		{config: graphql.BatchConfig{Window: 50 * time.Millisecond}, wantRequests: 1},
+		{config: graphql.BatchConfig{Window: time.Second, MaxSize: 2}, wantRequests: 2},
+	}
+	for _, tc := range tests {
+		atomic.StoreInt32(&requests, 0)
+		atomic.StoreInt32(&batched, 0)
+		client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+			WithBatching(tc.config)
+
+		logins := []string{"a", "b", "unknown", "c"}
+		names := make([]string, len(logins))
+		errs := make([]error, len(logins))
+		var wg sync.WaitGroup
+		for i, login := range logins {
+			wg.Add(1)
+			go func(i int, login string) {
+				defer wg.Done()
+				names[i], errs[i] = query(client, login)
+			}(i, login)
+		}
+		wg.Wait()
+		if got := atomic.LoadInt32(&requests); got != tc.wantRequests {
+			t.Errorf("%+v: got %d requests, want %d", tc.config, got, tc.wantRequests)
+		}
+		if got := atomic.LoadInt32(&batched); got != 4 {
+			t.Errorf("%+v: got %d batched operations, want 4", tc.config, got)
+		}
+		for i, login := range logins {
+			if login == "unknown" {
+				if errs[i] == nil || errs[i].Error() != "Message: not found, Locations: []" {
+					t.Errorf("%+v: got error %v for unknown login", tc.config, errs[i])
+				}
+				continue
+			}
+			if errs[i] != nil || names[i] != "Name of "+login {
+				t.Errorf("%+v: got %q, %v for login %s", tc.config, names[i], errs[i], login)
+			}
+		}
+
+		// Mutations are sent alone.
+		var m struct {
+			AddStar struct {
+				ID string
+			} `graphql:"addStar(id: \"1\")"`
+		}
+		if err := client.Mutate(context.Background(), &m, nil); err != nil || m.AddStar.ID != "1" {
+			t.Errorf("%+v: got %+v, %v", tc.config, m, err)
+		}
+	}
+}
+
+func TestClient_WithBatching_cancel(t *testing.T) {
+	var (
+		batched  int32
+		canceled = make(chan struct{})
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		var in []json.RawMessage
+		if err := json.Unmarshal([]byte(mustRead(req.Body)), &in); err != nil {
+			t.Error(err)
+		}
+		atomic.StoreInt32(&batched, int32(len(in)))
+		<-req.Context().Done()
+		close(canceled)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithBatching(graphql.BatchConfig{Window: 50 * time.Millisecond})
+
+	query := func(ctx context.Context, login string) error {
+		var q struct {
+			User struct {
+				Name string
+			} `graphql:"user(login: $login)"`
+		}
+		return client.Query(ctx, &q, map[string]interface{}{"login": login})
+	}
+	// The query of a caller that gives up before the batch is sent is left out.
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for _, ctx := range []context.Context{ctx1, ctx2} {
+		wg.Add(1)
+		go func(ctx context.Context) {
+			defer wg.Done()
+			if err := query(ctx, "gopher"); err == nil {
+				t.Error("got no error for a canceled query")
+			}
+		}(ctx)
+	}
+	time.Sleep(10 * time.Millisecond)
+	cancel1()
+	waitFor(t, func() bool { return atomic.LoadInt32(&batched) > 0 })
+	if got := atomic.LoadInt32(&batched); got != 1 {
+		t.Errorf("got %d batched operations, want 1", got)
+	}
+
+	// The batch is canceled once no caller waits for it.
+	cancel2()
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("got no cancellation of the batch")
+	}
+	wg.Wait()
+}
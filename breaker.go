@@ -0,0 +1,337 @@
+package graphql
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of the circuit breaker of an endpoint.
+type CircuitState int
+
+const (
+	// CircuitClosed lets requests through, and counts their failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails requests fast, without sending them.
+	CircuitOpen
+	// CircuitHalfOpen lets a few trial requests through, which close the circuit if they succeed
+	// or open it again if one of them fails.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// FailureClass is a set of classes of errors, which count as failures of requests.
+type FailureClass int
+
+const (
+	// FailNetwork counts network errors, e.g. refused connections and timeouts.
+	FailNetwork FailureClass = 1 << iota
+	// FailServerError counts HTTP 5xx responses.
+	FailServerError
+	// FailGraphQLErrors counts responses with GraphQL errors.
+	FailGraphQLErrors
+)
+
+// CircuitBreaker configures the circuit breakers of a client, one per endpoint.
+type CircuitBreaker struct {
+	// ConsecutiveFailures opens the circuit after this number of consecutive failures. Zero disables it.
+	ConsecutiveFailures int
+	// FailureRate opens the circuit when the rate of failed requests within the window reaches it,
+	// between 0 and 1. Zero disables it.
+	FailureRate float64
+	// MinRequests is the number of requests within the window below which the failure rate doesn't apply, 10 if zero.
+	MinRequests int
+	// Window is the period over which the failure rate is computed, 1m if zero.
+	Window time.Duration
+	// OpenTimeout is the time the circuit stays open before it's half-open, 30s if zero.
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of trial requests of a half-open circuit, 1 if zero.
+	HalfOpenRequests int
+	// Failures is the classes of errors that count as failures, FailNetwork|FailServerError if zero.
+	Failures FailureClass
+	// OnStateChange, if set, is called when the circuit of an endpoint changes state.
+	OnStateChange func(endpoint string, from, to CircuitState)
+}
+
+// CircuitOpenError is the error of a request to an endpoint whose circuit is open.
+type CircuitOpenError struct {
+	Endpoint string
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker of %s is open", e.Endpoint)
+}
+
+// WithCircuitBreaker returns a copy of the client that sends requests through a circuit breaker per endpoint.
+// The circuit of an endpoint opens after consecutive failures, or when its rate of failures is too high.
+// While it's open, requests fail fast with an error whose code is ErrCircuitOpen. After the open timeout,
+// the circuit is half-open: trial requests are sent, and it closes if they succeed.
+//
+// Requests canceled by their context don't count. With WithFailover, requests fail over from endpoints
+// whose circuit is open.
+func (c *Client) WithCircuitBreaker(breaker CircuitBreaker) *Client {
+	if breaker.MinRequests <= 0 {
+		breaker.MinRequests = 10
+	}
+	if breaker.Window <= 0 {
+		breaker.Window = time.Minute
+	}
+	if breaker.OpenTimeout <= 0 {
+		breaker.OpenTimeout = 30 * time.Second
+	}
+	if breaker.HalfOpenRequests <= 0 {
+		breaker.HalfOpenRequests = 1
+	}
+	if breaker.Failures == 0 {
+		breaker.Failures = FailNetwork | FailServerError
+	}
+	nc := *c
+	nc.breakers = &circuitBreakers{config: breaker, circuits: make(map[string]*circuit)}
+	return &nc
+}
+
+// sendEndpoint sends req to its endpoint, through the circuit breaker of the endpoint if any.
+// It's signed first if the client has a signer.
+func (c *Client) sendEndpoint(req *http.Request) (*http.Response, error) {
+	if c.signer != nil {
+		var err error
+		if req, err = c.signRequest(req); err != nil {
+			return nil, err
+		}
+	}
+	if c.breakers == nil {
+		return c.httpClient.Do(req)
+	}
+	return c.breakers.do(c.httpClient, req)
+}
+
+// requestError returns the error of a request that failed with err.
+func requestError(err error) Error {
+	var open *CircuitOpenError
+	if errors.As(err, &open) {
+		return newError(ErrCircuitOpen, err)
+	}
+	return newError(ErrRequestError, err)
+}
+
+// circuitBreakers holds the circuits of the endpoints.
+type circuitBreakers struct {
+	config   CircuitBreaker
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+// circuit is the state of the circuit breaker of an endpoint.
+type circuit struct {
+	state       CircuitState
+	openedAt    time.Time
+	consecutive int
+	windowStart time.Time
+	requests    int
+	failures    int
+	// trials and successes count the trial requests of a half-open circuit.
+	trials    int
+	successes int
+}
+
+type stateChange struct {
+	from, to CircuitState
+}
+
+// outcome is the result of a request for its circuit.
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota
+	outcomeFailure
+	// outcomeIgnored is the outcome of canceled requests.
+	outcomeIgnored
+)
+
+// do sends req, unless the circuit of its endpoint is open, and records its outcome.
+func (b *circuitBreakers) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	u := *req.URL
+	u.RawQuery, u.Fragment = "", ""
+	endpoint := u.String()
+
+	if !b.allow(endpoint) {
+		return nil, &CircuitOpenError{Endpoint: endpoint}
+	}
+	resp, err := client.Do(req)
+	result := outcomeSuccess
+	switch {
+	case req.Context().Err() != nil:
+		result = outcomeIgnored
+	case err != nil:
+		if b.config.Failures&FailNetwork != 0 {
+			result = outcomeFailure
+		}
+	case resp.StatusCode >= http.StatusInternalServerError:
+		if b.config.Failures&FailServerError != 0 {
+			result = outcomeFailure
+		}
+	case b.config.Failures&FailGraphQLErrors != 0:
+		failed, readErr := hasGraphQLErrors(resp)
+		if readErr != nil {
+			resp, err = nil, readErr
+		}
+		if failed {
+			result = outcomeFailure
+		}
+	}
+	b.record(endpoint, result)
+	return resp, err
+}
+
+// allow reports whether a request to the endpoint may be sent.
+func (b *circuitBreakers) allow(endpoint string) bool {
+	var changes []stateChange
+	defer func() { b.notify(endpoint, changes) }()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[endpoint]
+	if !ok {
+		c = &circuit{windowStart: time.Now()}
+		b.circuits[endpoint] = c
+	}
+	if c.state == CircuitOpen {
+		if time.Since(c.openedAt) < b.config.OpenTimeout {
+			return false
+		}
+		c.state, c.trials, c.successes = CircuitHalfOpen, 0, 0
+		changes = append(changes, stateChange{CircuitOpen, CircuitHalfOpen})
+	}
+	if c.state == CircuitHalfOpen {
+		if c.trials >= b.config.HalfOpenRequests {
+			return false
+		}
+		c.trials++
+	}
+	return true
+}
+
+// record records the outcome of a request to the endpoint.
+func (b *circuitBreakers) record(endpoint string, result outcome) {
+	var changes []stateChange
+	defer func() { b.notify(endpoint, changes) }()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuits[endpoint]
+	now := time.Now()
+	switch c.state {
+	case CircuitHalfOpen:
+		switch result {
+		case outcomeIgnored:
+			c.trials--
+		case outcomeFailure:
+			c.state, c.openedAt = CircuitOpen, now
+			changes = append(changes, stateChange{CircuitHalfOpen, CircuitOpen})
+		case outcomeSuccess:
+			c.successes++
+			if c.successes >= b.config.HalfOpenRequests {
+				*c = circuit{state: CircuitClosed, windowStart: now}
+				changes = append(changes, stateChange{CircuitHalfOpen, CircuitClosed})
+			}
+		}
+	case CircuitClosed:
+		if result == outcomeIgnored {
+			return
+		}
+		if now.Sub(c.windowStart) >= b.config.Window {
+			c.windowStart, c.requests, c.failures = now, 0, 0
+		}
+		c.requests++
+		if result == outcomeSuccess {
+			c.consecutive = 0
+			return
+		}
+		c.failures++
+		c.consecutive++
+		cfg := b.config
+		if (cfg.ConsecutiveFailures > 0 && c.consecutive >= cfg.ConsecutiveFailures) ||
+			(cfg.FailureRate > 0 && c.requests >= cfg.MinRequests && float64(c.failures)/float64(c.requests) >= cfg.FailureRate) {
+			c.state, c.openedAt = CircuitOpen, now
+			changes = append(changes, stateChange{CircuitClosed, CircuitOpen})
+		}
+	}
+}
+
+func (b *circuitBreakers) notify(endpoint string, changes []stateChange) {
+	if b.config.OnStateChange == nil {
+		return
+	}
+	for _, change := range changes {
+		b.config.OnStateChange(endpoint, change.from, change.to)
+	}
+}
+
+// hasGraphQLErrors reports whether the response, or one of the responses of a batch, has errors.
+func hasGraphQLErrors(resp *http.Response) (bool, error) {
+	body, err := readBody(resp)
+	if err != nil || body == nil {
+		return false, err
+	}
+	type response struct {
+		Errors []json.RawMessage `json:"errors"`
+	}
+	if body[0] == '[' {
+		var responses []response
+		if json.Unmarshal(body, &responses) != nil {
+			return false, nil
+		}
+		for _, r := range responses {
+			if len(r.Errors) > 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	var r response
+	return json.Unmarshal(body, &r) == nil && len(r.Errors) > 0, nil
+}
+
+// readBody reads the body of the response, which is buffered to be read again, and returns it
+// decompressed and trimmed. It returns a nil body if the body is empty or can't be decompressed.
+func readBody(resp *http.Response) ([]byte, error) {
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		gr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, nil
+		}
+		defer gr.Close()
+		if body, err = ioutil.ReadAll(gr); err != nil {
+			return nil, nil
+		}
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, nil
+	}
+	return body, nil
+}
@@ -0,0 +1,142 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zainirfan13/graphql-client"
+)
+
+func TestClient_WithCircuitBreaker(t *testing.T) {
+	var requests int32
+	var status int32 = http.StatusInternalServerError
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+
+	var (
+		mu      sync.Mutex
+		changes []string
+	)
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithCircuitBreaker(graphql.CircuitBreaker{
+			ConsecutiveFailures: 2,
+			OpenTimeout:         50 * time.Millisecond,
+			OnStateChange: func(endpoint string, from, to graphql.CircuitState) {
+				mu.Lock()
+				defer mu.Unlock()
+				changes = append(changes, endpoint+": "+from.String()+" -> "+to.String())
+			},
+		})
+
+	query := func() error {
+		var q struct {
+			Viewer struct {
+				Login string
+			}
+		}
+		return client.Query(context.Background(), &q, nil)
+	}
+	code := func(err error) interface{} {
+		if errs, ok := err.(graphql.Errors); ok && len(errs) > 0 {
+			return errs[0].Extensions["code"]
+		}
+		return nil
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := query(); code(err) != graphql.ErrRequestError {
+			t.Fatalf("got error %v, want a request error", err)
+		}
+	}
+	// The circuit is open: the request fails fast.
+	if err := query(); code(err) != graphql.ErrCircuitOpen {
+		t.Fatalf("got error %v, want a circuit open error", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+
+	// The trial request of the half-open circuit fails, which opens it again.
+	time.Sleep(60 * time.Millisecond)
+	if err := query(); code(err) != graphql.ErrRequestError {
+		t.Fatalf("got error %v, want a request error", err)
+	}
+	if err := query(); code(err) != graphql.ErrCircuitOpen {
+		t.Fatalf("got error %v, want a circuit open error", err)
+	}
+
+	// The trial request succeeds, which closes it.
+	atomic.StoreInt32(&status, http.StatusOK)
+	time.Sleep(60 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if err := query(); err != nil {
+			t.Fatalf("got error %v", err)
+		}
+	}
+
+	want := []string{
+		"/graphql: closed -> open",
+		"/graphql: open -> half-open",
+		"/graphql: half-open -> open",
+		"/graphql: open -> half-open",
+		"/graphql: half-open -> closed",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("got state changes %q, want %q", changes, want)
+	}
+}
+
+func TestClient_WithCircuitBreaker_failureRate(t *testing.T) {
+	var requests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		if n%2 == 0 {
+			mustWrite(w, `{"data": null, "errors": [{"message": "upstream unavailable"}]}`)
+			return
+		}
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithCircuitBreaker(graphql.CircuitBreaker{
+			FailureRate: 0.5,
+			MinRequests: 4,
+			Failures:    graphql.FailGraphQLErrors,
+		})
+
+	var q struct {
+		Viewer struct {
+			Login string
+		}
+	}
+	for i := 0; i < 4; i++ {
+		err := client.Query(context.Background(), &q, nil)
+		if i%2 == 0 && err != nil {
+			t.Fatalf("request %d: got error %v", i, err)
+		}
+		if i%2 == 1 && (err == nil || err.Error() != "Message: upstream unavailable, Locations: []") {
+			t.Fatalf("request %d: got error %v, want the GraphQL error", i, err)
+		}
+	}
+	err := client.Query(context.Background(), &q, nil)
+	if errs, ok := err.(graphql.Errors); !ok || errs[0].Extensions["code"] != graphql.ErrCircuitOpen {
+		t.Errorf("got error %v, want a circuit open error", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 4 {
+		t.Errorf("got %d requests, want 4", got)
+	}
+}
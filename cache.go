@@ -0,0 +1,599 @@
+package graphql
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FetchPolicy tells whether an operation reads its result from the normalized cache,
+// from the server, or both. It's an Option, so it can be set per call:
+//
+//	err := client.Query(ctx, &q, variables, graphql.CacheAndNetwork)
+//
+// Fetch policies only apply to queries; mutations are always sent to the server.
+type FetchPolicy string
+
+const (
+	// CacheFirst reads the result from the cache, and sends the query only if the cache misses some fields.
+	CacheFirst FetchPolicy = "cache-first"
+	// CacheAndNetwork reads the result from the cache, and refreshes the cache in the background.
+	// If the cache misses some fields, the query is sent as with CacheFirst.
+	CacheAndNetwork FetchPolicy = "cache-and-network"
+	// NetworkOnly sends the query, and writes its result to the cache.
+	NetworkOnly FetchPolicy = "network-only"
+	// CacheOnly reads the result from the cache, and fails with an ErrCacheMiss error
+	// if the cache misses some fields.
+	CacheOnly FetchPolicy = "cache-only"
+)
+
+// Type implements Option interface.
+func (p FetchPolicy) Type() OptionType {
+	return optionTypeFetchPolicy
+}
+
+// String implements Option interface.
+func (p FetchPolicy) String() string {
+	return string(p)
+}
+
+// CacheStore stores the records of the normalized cache, as JSON objects.
+// Implementations must be safe for concurrent use.
+type CacheStore interface {
+	// Get returns the record with the key, and false if there is none or it expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores the record with the key. A zero ttl means the record doesn't expire.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// MemoryCacheStore is a CacheStore that keeps records in memory,
+// up to a maximum number of records. When it's full, the least recently
+// used records are evicted.
+type MemoryCacheStore struct {
+	mu         sync.Mutex
+	maxRecords int
+	records    map[string]*list.Element
+	// lru holds the records from the most to the least recently used.
+	lru *list.List
+}
+
+type memoryCacheRecord struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// DefaultMemoryCacheRecords is the maximum number of records of a MemoryCacheStore by default.
+const DefaultMemoryCacheRecords = 10000
+
+// NewMemoryCacheStore creates an empty MemoryCacheStore that keeps up to maxRecords records,
+// or DefaultMemoryCacheRecords if maxRecords isn't positive.
+func NewMemoryCacheStore(maxRecords int) *MemoryCacheStore {
+	if maxRecords <= 0 {
+		maxRecords = DefaultMemoryCacheRecords
+	}
+	return &MemoryCacheStore{
+		maxRecords: maxRecords,
+		records:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Get implements CacheStore interface.
+func (s *MemoryCacheStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.records[key]
+	if !ok {
+		return nil, false, nil
+	}
+	r := e.Value.(*memoryCacheRecord)
+	if !r.expires.IsZero() && !time.Now().Before(r.expires) {
+		s.lru.Remove(e)
+		delete(s.records, key)
+		return nil, false, nil
+	}
+	s.lru.MoveToFront(e)
+	return r.value, true, nil
+}
+
+// Set implements CacheStore interface.
+func (s *MemoryCacheStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	r := &memoryCacheRecord{key: key, value: value}
+	if ttl > 0 {
+		r.expires = time.Now().Add(ttl)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.records[key]; ok {
+		e.Value = r
+		s.lru.MoveToFront(e)
+		return nil
+	}
+	s.records[key] = s.lru.PushFront(r)
+	for s.lru.Len() > s.maxRecords {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.records, oldest.Value.(*memoryCacheRecord).key)
+	}
+	return nil
+}
+
+// CacheConfig configures the normalized cache of a client.
+type CacheConfig struct {
+	// Store stores the records, in memory if nil.
+	Store CacheStore
+	// TTL is the time to live of records, which don't expire if zero.
+	TTL time.Duration
+	// FetchPolicy is the default fetch policy of queries, CacheFirst if empty.
+	FetchPolicy FetchPolicy
+	// RefreshTimeout is the timeout of the background refreshes of CacheAndNetwork queries,
+	// 30 seconds if zero.
+	RefreshTimeout time.Duration
+}
+
+// defaultRefreshTimeout is the RefreshTimeout of caches that don't set one.
+const defaultRefreshTimeout = 30 * time.Second
+
+// WithCache returns a copy of the client with a normalized cache.
+//
+// Objects of responses are stored in records keyed by their __typename and id, e.g. User:1,
+// and the root fields of queries in the ROOT_QUERY record, by field name and arguments.
+// Objects without id are stored in the record of their parent. Queries are read back from
+// the records, so the objects updated by a mutation response are updated in every query.
+// __typename is added to every selection set of the operations, and removed from the results
+// unless it was selected. Responses with errors aren't cached.
+//
+// The cache only applies to the operations built by the client, not to Exec.
+// Store errors are ignored, as cache misses.
+//
+// CacheAndNetwork queries are refreshed at most once at a time for the same query and variables,
+// within the RefreshTimeout.
+func (c *Client) WithCache(config CacheConfig) *Client {
+	if config.Store == nil {
+		config.Store = NewMemoryCacheStore(0)
+	}
+	if config.FetchPolicy == "" {
+		config.FetchPolicy = CacheFirst
+	}
+	if config.RefreshTimeout <= 0 {
+		config.RefreshTimeout = defaultRefreshTimeout
+	}
+	nc := *c
+	nc.cache = &config
+	nc.cacheRefreshes = &cacheRefreshes{keys: make(map[string]bool)}
+	return &nc
+}
+
+// cacheRefreshes holds the keys of the queries being refreshed in the background.
+type cacheRefreshes struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+// start reports whether the refresh of key can start, i.e. it isn't being refreshed already.
+func (r *cacheRefreshes) start(key string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.keys[key] {
+		return false
+	}
+	r.keys[key] = true
+	return true
+}
+
+func (r *cacheRefreshes) done(key string) {
+	r.mu.Lock()
+	delete(r.keys, key)
+	r.mu.Unlock()
+}
+
+// refresh sends the operation query in the background to refresh the cache,
+// unless the same query with the same variables is being refreshed.
+func (c *Client) refresh(op operationType, doc *astDocument, query string, variables map[string]interface{}, encoded map[string]interface{}, options ...Option) {
+	b, err := json.Marshal(encoded)
+	if err != nil {
+		return
+	}
+	key := query + "\n" + string(b)
+	if !c.cacheRefreshes.start(key) {
+		return
+	}
+	go func() {
+		defer c.cacheRefreshes.done(key)
+		ctx, cancel := context.WithTimeout(context.Background(), c.cache.RefreshTimeout)
+		defer cancel()
+		c.cacheFetch(ctx, op, doc, query, variables, encoded, options...)
+	}()
+}
+
+// rootQueryKey is the key of the record of the root fields of queries.
+const rootQueryKey = "ROOT_QUERY"
+
+// cacheRequest sends the operation query, or reads its result from the cache, according to the fetch policy.
+func (c *Client) cacheRequest(ctx context.Context, op operationType, query string, variables map[string]interface{}, options ...Option) ([]byte, *http.Response, io.Reader, Errors) {
+	doc, err := parseDocument(query)
+	if err != nil || len(doc.operations) != 1 {
+		return c.send(ctx, op, query, variables, options...)
+	}
+	encoded, err := requestVariables(variables)
+	if err != nil {
+		return nil, nil, nil, Errors{newError(ErrGraphQLEncode, err)}
+	}
+	policy := c.cache.FetchPolicy
+	for _, option := range options {
+		if p, ok := option.(FetchPolicy); ok {
+			policy = p
+		}
+	}
+
+	if op == queryOperation && policy != NetworkOnly {
+		r := cacheReader{ctx: ctx, store: c.cache.Store, doc: doc, variables: encoded}
+		if data, ok := r.readRoot(); ok {
+			if policy == CacheAndNetwork {
+				c.refresh(op, doc, query, variables, encoded, options...)
+			}
+			return data, nil, nil, nil
+		}
+		if policy == CacheOnly {
+			return nil, nil, nil, Errors{newError(ErrCacheMiss, fmt.Errorf("cache misses fields of the query"))}
+		}
+	}
+	return c.cacheFetch(ctx, op, doc, query, variables, encoded, options...)
+}
+
+// cacheFetch sends the operation query with __typename in every selection set, writes the result to the cache,
+// and returns the data of query.
+func (c *Client) cacheFetch(ctx context.Context, op operationType, doc *astDocument, query string, variables map[string]interface{}, encoded map[string]interface{}, options ...Option) ([]byte, *http.Response, io.Reader, Errors) {
+	typenameQuery := withTypename(query, doc)
+	typenameDoc, err := parseDocument(typenameQuery)
+	if err != nil {
+		return c.send(ctx, op, query, variables, options...)
+	}
+	data, resp, respBuf, errs := c.send(ctx, op, typenameQuery, variables, options...)
+	if len(data) == 0 {
+		return data, resp, respBuf, errs
+	}
+	if len(errs) == 0 {
+		w := cacheWriter{ctx: ctx, config: c.cache, doc: typenameDoc, variables: encoded, records: make(map[string]map[string]json.RawMessage)}
+		w.writeRoot(data, op == queryOperation)
+	}
+	if projected, err := projectData(doc, doc.operation.selections, data); err == nil {
+		data = projected
+	}
+	return data, resp, respBuf, errs
+}
+
+// withTypename adds __typename to the selection sets of fields and fragments of query.
+func withTypename(query string, doc *astDocument) string {
+	offsets := append([]int(nil), doc.selectionSets...)
+	sort.Ints(offsets)
+	var b strings.Builder
+	last := 0
+	for _, offset := range offsets {
+		b.WriteString(query[last:offset])
+		b.WriteString("__typename,")
+		last = offset
+	}
+	b.WriteString(query[last:])
+	return b.String()
+}
+
+// fieldKey returns the key of the field s in records, made of its name and arguments.
+func fieldKey(s *astSelection, variables map[string]interface{}) string {
+	if len(s.arguments) == 0 {
+		return s.name
+	}
+	names := make([]string, 0, len(s.arguments))
+	for name := range s.arguments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	args := make([]string, len(names))
+	for i, name := range names {
+		args[i] = strconv.Quote(name) + ":" + s.arguments[name].canonical(variables)
+	}
+	return s.name + "({" + strings.Join(args, ",") + "})"
+}
+
+// entityKey returns the key of the record of the object, if it has a __typename and an id.
+func entityKey(object map[string]json.RawMessage) (string, bool) {
+	var typename string
+	if json.Unmarshal(object["__typename"], &typename) != nil || typename == "" {
+		return "", false
+	}
+	id, ok := object["id"]
+	if !ok || string(id) == "null" {
+		return "", false
+	}
+	var s string
+	if json.Unmarshal(id, &s) == nil {
+		return typename + ":" + s, true
+	}
+	return typename + ":" + string(id), true
+}
+
+// cacheWriter normalizes responses into records.
+type cacheWriter struct {
+	ctx       context.Context
+	config    *CacheConfig
+	doc       *astDocument
+	variables map[string]interface{}
+	// records holds the records written so far, by key.
+	records map[string]map[string]json.RawMessage
+}
+
+// writeRoot writes data, and stores the root fields if root is set.
+func (w *cacheWriter) writeRoot(data []byte, root bool) {
+	var object map[string]json.RawMessage
+	if json.Unmarshal(data, &object) != nil || object == nil {
+		return
+	}
+	record := w.record(rootQueryKey)
+	w.writeObject(w.doc.operation.selections, object, record)
+	if !root {
+		delete(w.records, rootQueryKey)
+	}
+	for key, record := range w.records {
+		b, err := json.Marshal(record)
+		if err != nil {
+			continue
+		}
+		_ = w.config.Store.Set(w.ctx, key, b, w.config.TTL)
+	}
+}
+
+// record returns the record with the key, loaded from the store the first time.
+func (w *cacheWriter) record(key string) map[string]json.RawMessage {
+	if record, ok := w.records[key]; ok {
+		return record
+	}
+	record := make(map[string]json.RawMessage)
+	if b, ok, err := w.config.Store.Get(w.ctx, key); err == nil && ok {
+		_ = json.Unmarshal(b, &record)
+	}
+	w.records[key] = record
+	return record
+}
+
+func (w *cacheWriter) writeObject(selections []*astSelection, object, record map[string]json.RawMessage) {
+	for _, s := range selections {
+		switch {
+		case s.spread != "":
+			w.writeObject(w.doc.fragments[s.spread], object, record)
+		case s.name == "":
+			w.writeObject(s.selections, object, record)
+		default:
+			value, ok := object[s.responseName()]
+			if !ok {
+				continue
+			}
+			record[fieldKey(s, w.variables)] = w.writeValue(s, value)
+		}
+	}
+}
+
+// writeValue returns the value of the field s to store in its record, with references to entities.
+func (w *cacheWriter) writeValue(s *astSelection, value json.RawMessage) json.RawMessage {
+	if len(s.selections) == 0 {
+		return value
+	}
+	var list []json.RawMessage
+	if json.Unmarshal(value, &list) == nil && list != nil {
+		for i, item := range list {
+			list[i] = w.writeValue(s, item)
+		}
+		if b, err := json.Marshal(list); err == nil {
+			return b
+		}
+		return value
+	}
+	var object map[string]json.RawMessage
+	if json.Unmarshal(value, &object) != nil || object == nil {
+		return value
+	}
+	if key, ok := entityKey(object); ok {
+		w.writeObject(s.selections, object, w.record(key))
+		b, _ := json.Marshal(map[string]string{"__ref": key})
+		return b
+	}
+	record := make(map[string]json.RawMessage)
+	w.writeObject(s.selections, object, record)
+	b, err := json.Marshal(record)
+	if err != nil {
+		return value
+	}
+	return b
+}
+
+// cacheReader reads the results of operations from records.
+type cacheReader struct {
+	ctx       context.Context
+	store     CacheStore
+	doc       *astDocument
+	variables map[string]interface{}
+}
+
+// readRoot returns the data of the operation, and false if the cache misses some fields.
+func (r *cacheReader) readRoot() ([]byte, bool) {
+	record, ok := r.record(rootQueryKey)
+	if !ok {
+		return nil, false
+	}
+	object, ok := r.readObject(r.doc.operation.selections, record)
+	if !ok {
+		return nil, false
+	}
+	b, err := json.Marshal(object)
+	return b, err == nil
+}
+
+func (r *cacheReader) record(key string) (map[string]json.RawMessage, bool) {
+	b, ok, err := r.store.Get(r.ctx, key)
+	if err != nil || !ok {
+		return nil, false
+	}
+	var record map[string]json.RawMessage
+	if json.Unmarshal(b, &record) != nil {
+		return nil, false
+	}
+	return record, true
+}
+
+func (r *cacheReader) readObject(selections []*astSelection, record map[string]json.RawMessage) (map[string]json.RawMessage, bool) {
+	object := make(map[string]json.RawMessage)
+	if !r.readSelections(selections, record, object) {
+		return nil, false
+	}
+	return object, true
+}
+
+// readSelections reads the selections from record into object.
+func (r *cacheReader) readSelections(selections []*astSelection, record, object map[string]json.RawMessage) bool {
+	for _, s := range selections {
+		switch {
+		case s.spread != "", s.name == "":
+			fragment := s.selections
+			if s.spread != "" {
+				fragment = r.doc.fragments[s.spread]
+			}
+			var typename string
+			_ = json.Unmarshal(record["__typename"], &typename)
+			if s.typeCondition == "" || s.typeCondition == typename {
+				if !r.readSelections(fragment, record, object) {
+					return false
+				}
+				continue
+			}
+			// The fragment may be on an interface or union of the type,
+			// so it's read only if all its fields are cached.
+			fields := make(map[string]json.RawMessage)
+			if r.readSelections(fragment, record, fields) {
+				for key, value := range fields {
+					object[key] = value
+				}
+			}
+		default:
+			value, ok := record[fieldKey(s, r.variables)]
+			if !ok {
+				return false
+			}
+			value, ok = r.readValue(s, value)
+			if !ok {
+				return false
+			}
+			if existing, ok := object[s.responseName()]; ok {
+				merged, err := mergeJSONObjects(existing, value)
+				if err != nil {
+					return false
+				}
+				value = merged
+			}
+			object[s.responseName()] = value
+		}
+	}
+	return true
+}
+
+func (r *cacheReader) readValue(s *astSelection, value json.RawMessage) (json.RawMessage, bool) {
+	if len(s.selections) == 0 {
+		return value, true
+	}
+	var list []json.RawMessage
+	if json.Unmarshal(value, &list) == nil && list != nil {
+		for i, item := range list {
+			v, ok := r.readValue(s, item)
+			if !ok {
+				return nil, false
+			}
+			list[i] = v
+		}
+		b, err := json.Marshal(list)
+		return b, err == nil
+	}
+	var record map[string]json.RawMessage
+	if json.Unmarshal(value, &record) != nil || record == nil {
+		return value, true
+	}
+	if ref, ok := record["__ref"]; ok && len(record) == 1 {
+		var key string
+		if json.Unmarshal(ref, &key) != nil {
+			return nil, false
+		}
+		if record, ok = r.record(key); !ok {
+			return nil, false
+		}
+	}
+	object, ok := r.readObject(s.selections, record)
+	if !ok {
+		return nil, false
+	}
+	b, err := json.Marshal(object)
+	return b, err == nil
+}
+
+// projectData returns data with the fields of the selections only.
+func projectData(doc *astDocument, selections []*astSelection, data json.RawMessage) (json.RawMessage, error) {
+	var list []json.RawMessage
+	if err := json.Unmarshal(data, &list); err == nil && list != nil {
+		for i, item := range list {
+			projected, err := projectData(doc, selections, item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = projected
+		}
+		return json.Marshal(list)
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil || object == nil {
+		return data, nil
+	}
+	projected := make(map[string]json.RawMessage)
+	if err := projectObject(doc, selections, object, projected); err != nil {
+		return nil, err
+	}
+	return json.Marshal(projected)
+}
+
+func projectObject(doc *astDocument, selections []*astSelection, object, projected map[string]json.RawMessage) error {
+	for _, s := range selections {
+		switch {
+		case s.spread != "":
+			if err := projectObject(doc, doc.fragments[s.spread], object, projected); err != nil {
+				return err
+			}
+		case s.name == "":
+			if err := projectObject(doc, s.selections, object, projected); err != nil {
+				return err
+			}
+		default:
+			value, ok := object[s.responseName()]
+			if !ok {
+				continue
+			}
+			if len(s.selections) > 0 {
+				var err error
+				if value, err = projectData(doc, s.selections, value); err != nil {
+					return err
+				}
+			}
+			if existing, ok := projected[s.responseName()]; ok {
+				var err error
+				if value, err = mergeJSONObjects(existing, value); err != nil {
+					return err
+				}
+			}
+			projected[s.responseName()] = value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zainirfan13/graphql-client"
+)
+
+func TestClient_WithCache(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	requested := make(chan struct{}, 10)
+	responses := map[string]string{
+		`{"query":"query ($id:ID!){user(id: $id){__typename,id,name,repositories(first: 2){__typename,totalCount}}}","variables":{"id":"1"}}` + "\n": `{"data": {"user": {"__typename": "User", "id": "1", "name": "Gopher", "repositories": {"__typename": "RepositoryConnection", "totalCount": 3}}}}`,
+		`{"query":"mutation{renameUser(id: \"1\", name: \"Go\"){__typename,user{__typename,id,name}}}"}` + "\n":                                      `{"data": {"renameUser": {"__typename": "RenameUserPayload", "user": {"__typename": "User", "id": "1", "name": "Go"}}}}`,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body := mustRead(req.Body)
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+		resp, ok := responses[body]
+		if !ok {
+			t.Errorf("unexpected body: %s", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, resp)
+		requested <- struct{}{}
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithCache(graphql.CacheConfig{})
+	requests := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(bodies)
+	}
+
+	type query struct {
+		User struct {
+			ID           graphql.ID
+			Name         string
+			Repositories struct {
+				TotalCount int
+			} `graphql:"repositories(first: 2)"`
+		} `graphql:"user(id: $id)"`
+	}
+	variables := map[string]interface{}{"id": graphql.ID("1")}
+	for i := 0; i < 2; i++ {
+		var q query
+		if err := client.Query(context.Background(), &q, variables); err != nil {
+			t.Fatal(err)
+		}
+		if q.User.Name != "Gopher" || q.User.Repositories.TotalCount != 3 {
+			t.Errorf("query %d: got %+v", i, q)
+		}
+	}
+	if got := requests(); got != 1 {
+		t.Errorf("got %d requests, want 1 for the cache-first queries", got)
+	}
+
+	// The mutation updates the cached user.
+	var m struct {
+		RenameUser struct {
+			User struct {
+				ID   graphql.ID
+				Name string
+			}
+		} `graphql:"renameUser(id: \"1\", name: \"Go\")"`
+	}
+	if err := client.Mutate(context.Background(), &m, nil); err != nil {
+		t.Fatal(err)
+	}
+	if m.RenameUser.User.Name != "Go" {
+		t.Errorf("got mutation result %+v", m)
+	}
+	var q query
+	if err := client.Query(context.Background(), &q, variables, graphql.CacheOnly); err != nil {
+		t.Fatal(err)
+	}
+	if q.User.Name != "Go" {
+		t.Errorf("got name %q from the cache, want the name of the mutation response", q.User.Name)
+	}
+	if got := requests(); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+
+	// Arguments are part of the cache keys.
+	err := client.Query(context.Background(), &q, map[string]interface{}{"id": graphql.ID("2")}, graphql.CacheOnly)
+	var errs graphql.Errors
+	if !errors.As(err, &errs) || errs[0].Extensions["code"] != graphql.ErrCacheMiss {
+		t.Errorf("got error: %v, want cache miss", err)
+	}
+
+	// network-only always sends the query.
+	<-requested
+	<-requested
+	if err := client.Query(context.Background(), &q, variables, graphql.NetworkOnly); err != nil {
+		t.Fatal(err)
+	}
+	if q.User.Name != "Gopher" {
+		t.Errorf("got name %q, want the name of the response", q.User.Name)
+	}
+	<-requested
+
+	// cache-and-network returns the cached result and refreshes it.
+	if err := client.Query(context.Background(), &q, variables, graphql.CacheAndNetwork); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-requested:
+	case <-time.After(time.Second):
+		t.Error("got no request to refresh the cache")
+	}
+	if got := requests(); got != 4 {
+		t.Errorf("got %d requests, want 4", got)
+	}
+}
+
+func TestMemoryCacheStore(t *testing.T) {
+	ctx := context.Background()
+	store := graphql.NewMemoryCacheStore(0)
+	if err := store.Set(ctx, "User:1", []byte(`{"id":"1"}`), time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set(ctx, "User:2", []byte(`{"id":"2"}`), 0); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok, err := store.Get(ctx, "User:1"); err != nil || !ok || string(got) != `{"id":"1"}` {
+		t.Errorf("got %s, %v, %v", got, ok, err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, ok, _ := store.Get(ctx, "User:1"); ok {
+		t.Error("got expired record")
+	}
+	if _, ok, _ := store.Get(ctx, "User:2"); !ok {
+		t.Error("got no record without TTL")
+	}
+}
+
+func TestMemoryCacheStore_maxRecords(t *testing.T) {
+	ctx := context.Background()
+	store := graphql.NewMemoryCacheStore(2)
+	store.Set(ctx, "User:1", []byte(`{"id":"1"}`), 0)
+	store.Set(ctx, "User:2", []byte(`{"id":"2"}`), 0)
+	// User:1 is used, so User:2 is the least recently used record.
+	store.Get(ctx, "User:1")
+	store.Set(ctx, "User:3", []byte(`{"id":"3"}`), 0)
+	for key, want := range map[string]bool{"User:1": true, "User:2": false, "User:3": true} {
+		if _, ok, _ := store.Get(ctx, key); ok != want {
+			t.Errorf("got record %s: %v, want %v", key, ok, want)
+		}
+	}
+}
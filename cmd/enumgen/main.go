@@ -0,0 +1,59 @@
+// enumgen generates Go types for the enums of a GraphQL schema.
+//
+// Usage:
+//
+//	enumgen -schema schema.graphql -package mypkg -o enums.go
+//
+// The schema may be written in the schema definition language
+// or be the JSON result of an introspection query.
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/zainirfan13/graphql-client/enumgen"
+)
+
+var (
+	schemaFlag   = flag.String("schema", "", "path of the GraphQL schema or introspection result (required)")
+	packageFlag  = flag.String("package", "", "package name of the generated file (required)")
+	outputFlag   = flag.String("o", "", "output file; if empty, the code is written to standard output")
+	fallbackFlag = flag.Bool("fallback", false, "map unknown enum values in responses to the empty value")
+)
+
+func main() {
+	flag.Parse()
+
+	err := run()
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func run() error {
+	if *schemaFlag == "" || *packageFlag == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	schema, err := ioutil.ReadFile(*schemaFlag)
+	if err != nil {
+		return err
+	}
+	src, err := enumgen.Generate(schema, enumgen.Options{
+		Package:  *packageFlag,
+		Fallback: *fallbackFlag,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *outputFlag == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return ioutil.WriteFile(*outputFlag, src, 0644)
+}
@@ -0,0 +1,292 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Complexity is the estimated complexity of an operation.
+type Complexity struct {
+	// Depth is the maximum nesting of fields, 1 for an operation that only selects root fields.
+	Depth int
+	// Fields is the number of fields in the operation, including the fields of fragments.
+	Fields int
+	// Cost is the estimated cost according to the CostModel.
+	Cost int
+}
+
+// CostModel estimates the cost of operations.
+//
+// The cost of a field is its weight plus the cost of its sub-selection,
+// multiplied by the list size taken from its first or last argument:
+//
+//	repository { issues(first: 50) { title } }
+//	// issues: 1 + 50*1 = 51, repository: 1 + 51 = 52
+type CostModel struct {
+	// Weights are the weights of fields, by path of field names from the root, e.g. "repository.issues",
+	// or by field name, e.g. "issues". Paths take precedence. Other fields weigh 1.
+	Weights map[string]int
+	// ListSizeArguments are the names of the arguments that set the size of lists,
+	// "first" and "last" by default.
+	ListSizeArguments []string
+	// DefaultListSize is the list size of fields whose list size argument is a variable without value.
+	// It's 1 if zero.
+	DefaultListSize int
+}
+
+// ComplexityBudget limits the complexity of the operations sent by a client.
+// Zero limits are ignored.
+type ComplexityBudget struct {
+	MaxDepth  int
+	MaxFields int
+	MaxCost   int
+	Model     CostModel
+}
+
+// ComplexityError is the error of an operation over the complexity budget.
+type ComplexityError struct {
+	Complexity Complexity
+	Budget     ComplexityBudget
+}
+
+// Error implements error interface.
+func (e *ComplexityError) Error() string {
+	var over []string
+	if e.Budget.MaxDepth > 0 && e.Complexity.Depth > e.Budget.MaxDepth {
+		over = append(over, fmt.Sprintf("depth %d > %d", e.Complexity.Depth, e.Budget.MaxDepth))
+	}
+	if e.Budget.MaxFields > 0 && e.Complexity.Fields > e.Budget.MaxFields {
+		over = append(over, fmt.Sprintf("fields %d > %d", e.Complexity.Fields, e.Budget.MaxFields))
+	}
+	if e.Budget.MaxCost > 0 && e.Complexity.Cost > e.Budget.MaxCost {
+		over = append(over, fmt.Sprintf("cost %d > %d", e.Complexity.Cost, e.Budget.MaxCost))
+	}
+	return "operation is over the complexity budget: " + strings.Join(over, ", ")
+}
+
+// check returns a *ComplexityError if c is over the budget.
+func (b ComplexityBudget) check(c Complexity) error {
+	if (b.MaxDepth > 0 && c.Depth > b.MaxDepth) ||
+		(b.MaxFields > 0 && c.Fields > b.MaxFields) ||
+		(b.MaxCost > 0 && c.Cost > b.MaxCost) {
+		return &ComplexityError{Complexity: c, Budget: b}
+	}
+	return nil
+}
+
+// WithComplexityBudget returns a copy of the client that refuses to send operations
+// over the budget, with an error whose code is ErrComplexityLimit.
+// The complexity is computed from the query and the variables, as with OperationComplexity.
+func (c *Client) WithComplexityBudget(budget ComplexityBudget) *Client {
+	nc := *c
+	nc.complexityBudget = &budget
+	return &nc
+}
+
+// QueryComplexity constructs the query of q, as Query does, and returns its complexity.
+func QueryComplexity(q interface{}, variables map[string]interface{}, model CostModel, options ...Option) (Complexity, error) {
+	query, err := ConstructQuery(q, variables, options...)
+	if err != nil {
+		return Complexity{}, err
+	}
+	vars, err := requestVariables(variables)
+	if err != nil {
+		return Complexity{}, err
+	}
+	return OperationComplexity(query, vars, model)
+}
+
+// OperationComplexity parses the GraphQL document query and returns the complexity of its first operation.
+// List sizes are read from literal arguments, variables, and the default values of variables.
+func OperationComplexity(query string, variables map[string]interface{}, model CostModel) (Complexity, error) {
+	doc, err := parseDocument(query)
+	if err != nil {
+		return Complexity{}, err
+	}
+	if doc.operation == nil {
+		return Complexity{}, fmt.Errorf("document has no operation")
+	}
+	return doc.complexity(doc.operation, variables, model)
+}
+
+// operationComplexity returns the complexity of the operation of query that is selected by name,
+// as the server selects it, i.e. the only operation if name is empty.
+func operationComplexity(query, name string, variables map[string]interface{}, model CostModel) (Complexity, error) {
+	doc, err := parseDocument(query)
+	if err != nil {
+		return Complexity{}, err
+	}
+	op, err := doc.operationNamed(name)
+	if err != nil {
+		return Complexity{}, err
+	}
+	return doc.complexity(op, variables, model)
+}
+
+// complexity returns the complexity of the operation op of doc.
+func (doc *astDocument) complexity(op *astOperation, variables map[string]interface{}, model CostModel) (Complexity, error) {
+	a := newComplexityAnalyzer(doc, op, variables, model)
+	var c Complexity
+	var err error
+	c.Cost, err = a.selectionCost(op.selections, "", 1, &c)
+	return c, err
+}
+
+type complexityAnalyzer struct {
+	model     CostModel
+	doc       *astDocument
+	operation *astOperation
+	variables map[string]interface{}
+	// spreading holds the named fragments being expanded, to detect cycles.
+	spreading map[string]bool
+}
+
+func newComplexityAnalyzer(doc *astDocument, op *astOperation, variables map[string]interface{}, model CostModel) *complexityAnalyzer {
+	if len(model.ListSizeArguments) == 0 {
+		model.ListSizeArguments = []string{"first", "last"}
+	}
+	if model.DefaultListSize == 0 {
+		model.DefaultListSize = 1
+	}
+	return &complexityAnalyzer{
+		model:     model,
+		doc:       doc,
+		operation: op,
+		variables: variables,
+		spreading: make(map[string]bool),
+	}
+}
+
+// selectionCost returns the cost of selections at depth, and adds its depth and fields to c.
+func (a *complexityAnalyzer) selectionCost(selections []*astSelection, path string, depth int, c *Complexity) (int, error) {
+	cost := 0
+	for _, s := range selections {
+		if s.spread != "" {
+			fragment, ok := a.doc.fragments[s.spread]
+			if !ok {
+				return 0, fmt.Errorf("fragment %s is not defined", s.spread)
+			}
+			if a.spreading[s.spread] {
+				return 0, fmt.Errorf("fragment %s spreads itself", s.spread)
+			}
+			a.spreading[s.spread] = true
+			fragmentCost, err := a.selectionCost(fragment, path, depth, c)
+			delete(a.spreading, s.spread)
+			if err != nil {
+				return 0, err
+			}
+			cost += fragmentCost
+			continue
+		}
+		if s.name == "" {
+			fragmentCost, err := a.selectionCost(s.selections, path, depth, c)
+			if err != nil {
+				return 0, err
+			}
+			cost += fragmentCost
+			continue
+		}
+
+		fieldPath := s.name
+		if path != "" {
+			fieldPath = path + "." + s.name
+		}
+		c.Fields++
+		if depth > c.Depth {
+			c.Depth = depth
+		}
+		childCost, err := a.selectionCost(s.selections, fieldPath, depth+1, c)
+		if err != nil {
+			return 0, err
+		}
+		cost += a.weight(s.name, fieldPath) + a.listSize(s)*childCost
+	}
+	return cost, nil
+}
+
+func (a *complexityAnalyzer) weight(name, path string) int {
+	if w, ok := a.model.Weights[path]; ok {
+		return w
+	}
+	if w, ok := a.model.Weights[name]; ok {
+		return w
+	}
+	return 1
+}
+
+// listSize returns the size of the list selected by the field s, or 1 if it has no list size argument.
+// Negative sizes, which servers reject, count as 0.
+func (a *complexityAnalyzer) listSize(s *astSelection) int {
+	n := a.argumentListSize(s)
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+func (a *complexityAnalyzer) argumentListSize(s *astSelection) int {
+	for _, name := range a.model.ListSizeArguments {
+		value, ok := s.arguments[name]
+		if !ok {
+			continue
+		}
+		if value.variable != "" {
+			if n, ok := intValue(a.variables[value.variable]); ok {
+				return n
+			}
+			if d, ok := a.operation.defaults[value.variable]; ok && d.isInt {
+				return d.n
+			}
+			return a.model.DefaultListSize
+		}
+		if value.isInt {
+			return value.n
+		}
+	}
+	return 1
+}
+
+// intValue returns the value of the variable v as an int, if it's an integer.
+// Pointers, Var and Omittable values are unwrapped, and unsigned integers
+// over the range of int are clamped to its maximum.
+func intValue(v interface{}) (int, bool) {
+	switch v := v.(type) {
+	case json.RawMessage:
+		n, err := strconv.Atoi(string(v))
+		return n, err == nil
+	case json.Number:
+		n, err := strconv.Atoi(string(v))
+		return n, err == nil
+	}
+	if v, ok := asVar(v); ok {
+		return intValue(v.Value)
+	}
+	if o, ok := asOmittable(v); ok {
+		return intValue(o.value)
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return 0, false
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n := rv.Uint(); n <= uint64(maxInt) {
+			return int(n), true
+		}
+		return maxInt, true
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		return int(f), f == float64(int(f))
+	}
+	return 0, false
+}
+
+const maxInt = int(^uint(0) >> 1)
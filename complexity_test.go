@@ -0,0 +1,183 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestOperationComplexity(t *testing.T) {
+	tests := []struct {
+		query     string
+		variables map[string]interface{}
+		model     CostModel
+		want      Complexity
+	}{
+		{
+			query: `{viewer{login}}`,
+			want:  Complexity{Depth: 2, Fields: 2, Cost: 2},
+		},
+		{
+			query:     `query ($first:Int!$after:String){repository(owner: "octocat", name: "Hello-World"){issues(first: $first, after: $after){nodes{title,labels(first: 10){nodes{name}}}}}}`,
+			variables: map[string]interface{}{"first": 50},
+			// labels: 1 + 10*(1+1) = 21, nodes: 1 + 1 + 21 = 23, issues: 1 + 50*23 = 1151.
+			want: Complexity{Depth: 6, Fields: 7, Cost: 1152},
+		},
+		{
+			query: `query Issues($last:Int=20){repository(owner: "octocat", name: "Hello-World"){a: issues(last: $last){totalCount}, b: issues{totalCount}}}`,
+			model: CostModel{Weights: map[string]int{"totalCount": 0, "repository.issues": 2}},
+			want:  Complexity{Depth: 3, Fields: 5, Cost: 5},
+		},
+		{
+			query: `
+				# Named and inline fragments.
+				query {
+					node(id: "1") {
+						...on Issue { title }
+						...Labels @include(if: true)
+					}
+				}
+				fragment Labels on Labelable { labels(first: $size) { nodes { name } } }
+			`,
+			model: CostModel{DefaultListSize: 5},
+			// labels: 1 + 5*(1+1) = 11, node: 1 + 1 + 11 = 13.
+			want: Complexity{Depth: 4, Fields: 5, Cost: 13},
+		},
+		{
+			query:     `mutation ($input:AddStarInput!){addStar(input: $input){starrable{stargazers(count: 3){totalCount}}}}`,
+			variables: map[string]interface{}{"input": map[string]interface{}{"starrableId": "1"}},
+			model:     CostModel{ListSizeArguments: []string{"count"}},
+			want:      Complexity{Depth: 4, Fields: 4, Cost: 6},
+		},
+		{
+			// Negative list sizes count as 0.
+			query:     `query ($first:Int!){viewer{repositories(first: $first){nodes{name}}}}`,
+			variables: map[string]interface{}{"first": -1000},
+			want:      Complexity{Depth: 4, Fields: 4, Cost: 2},
+		},
+	}
+	for i, tc := range tests {
+		got, err := OperationComplexity(tc.query, tc.variables, tc.model)
+		if err != nil {
+			t.Errorf("test case %d: %v", i, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("test case %d: got %+v, want %+v", i, got, tc.want)
+		}
+	}
+}
+
+func TestIntValue(t *testing.T) {
+	n, v := 7, Var{Value: 8}
+	tests := []struct {
+		in   interface{}
+		want int
+		ok   bool
+	}{
+		{in: 1, want: 1, ok: true},
+		{in: int8(2), want: 2, ok: true},
+		{in: int16(3), want: 3, ok: true},
+		{in: uint32(4), want: 4, ok: true},
+		{in: uint64(1 << 63), want: maxInt, ok: true},
+		{in: 5.0, want: 5, ok: true},
+		{in: 5.5, want: 5},
+		{in: json.RawMessage("6"), want: 6, ok: true},
+		{in: &n, want: 7, ok: true},
+		{in: (*int)(nil)},
+		{in: &v, want: 8, ok: true},
+		{in: Present(&n), want: 7, ok: true},
+		{in: "10"},
+		{in: nil},
+	}
+	for i, tc := range tests {
+		got, ok := intValue(tc.in)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("test case %d: got %d, %t, want %d, %t", i, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestOperationComplexity_errors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: `fragment A on T { id }`, want: "document has no operation"},
+		{query: `{ a(first: 1 }`, want: `syntax error at offset 13: expected name, got "}"`},
+		{query: `{ a ? }`, want: "syntax error at offset 4: unexpected character '?'"},
+		{query: `{ a(s: "x) }`, want: "syntax error at offset 7: unterminated string"},
+		{query: `{ ...A }`, want: "fragment A is not defined"},
+		{query: `{ ...A } fragment A on T { b { ...A } }`, want: "fragment A spreads itself"},
+	}
+	for _, tc := range tests {
+		_, err := OperationComplexity(tc.query, nil, CostModel{})
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("%s: got error: %v, want: %s", tc.query, err, tc.want)
+		}
+	}
+}
+
+func TestQueryComplexity(t *testing.T) {
+	var q struct {
+		Repository struct {
+			Issues struct {
+				Nodes []struct {
+					Title string
+				}
+			} `graphql:"issues(first: $first)"`
+		} `graphql:"repository(owner: \"octocat\", name: \"Hello-World\")"`
+	}
+	got, err := QueryComplexity(&q, map[string]interface{}{"first": Var{Value: 100, Type: "Int"}}, CostModel{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Complexity{Depth: 4, Fields: 4, Cost: 202}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClient_WithComplexityBudget(t *testing.T) {
+	client := NewClient("/graphql", &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		t.Error("got request over the budget")
+		return nil, errors.New("unexpected request")
+	})}).WithComplexityBudget(ComplexityBudget{MaxCost: 100, MaxDepth: 3})
+
+	var q struct {
+		Viewer struct {
+			Repositories struct {
+				TotalCount int
+			} `graphql:"repositories(first: $first)"`
+		}
+	}
+	err := client.Query(context.Background(), &q, map[string]interface{}{"first": 200})
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatalf("got error: %v, want Errors", err)
+	}
+	if got := errs[0].Extensions["code"]; got != ErrComplexityLimit {
+		t.Errorf("got code: %v, want: %s", got, ErrComplexityLimit)
+	}
+	if got, want := errs[0].Message, "operation is over the complexity budget: cost 202 > 100"; got != want {
+		t.Errorf("got message: %q, want: %q", got, want)
+	}
+	var complexityErr *ComplexityError
+	if !errors.As(err, &complexityErr) || complexityErr.Complexity.Cost != 202 {
+		t.Errorf("got error: %v, want *ComplexityError", err)
+	}
+
+	// The operation selected by the operation name is analyzed.
+	query := `query Small{viewer{login}} query Large{viewer{repositories(first: 200){nodes{name}}}}`
+	err = client.Exec(context.Background(), query, &q, nil, OperationName("Large"))
+	if !errors.As(err, &complexityErr) || complexityErr.Complexity.Cost != 402 {
+		t.Errorf("got error: %v, want *ComplexityError of Large", err)
+	}
+}
@@ -0,0 +1,111 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WithDeduplication returns a copy of the client that coalesces identical queries in flight
+// into one HTTP request. Queries are identical if their requests have the same method, URL,
+// headers and body, i.e. the same query text and variables. Each caller decodes the shared
+// response into its own target. Mutations are never deduplicated.
+//
+// The request is sent with the values of the context of the first caller, and is canceled
+// once every caller stopped waiting for it. Callers stop waiting when their own context is done.
+func (c *Client) WithDeduplication(deduplicate bool) *Client {
+	nc := *c
+	nc.inflight = nil
+	if deduplicate {
+		nc.inflight = &inflightGroup{calls: make(map[string]*inflightCall)}
+	}
+	return &nc
+}
+
+// inflightGroup tracks the requests in flight by key.
+type inflightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*inflightCall
+}
+
+// inflightCall is a request in flight, whose result is shared once done is closed.
+type inflightCall struct {
+	done chan struct{}
+	// waiters is the number of callers waiting for the result, guarded by the mutex of the group.
+	waiters int
+	cancel  context.CancelFunc
+
+	data    []byte
+	resp    *http.Response
+	respBuf io.Reader
+	// respBody is the body read from respBuf in debug mode.
+	respBody []byte
+	errs     Errors
+}
+
+// do calls fn, unless a call with the same key is in flight, in which case it waits for its result.
+// fn is called with a context that has the values of ctx, and is canceled when no caller waits anymore.
+func (g *inflightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) ([]byte, *http.Response, io.Reader, Errors)) ([]byte, *http.Response, io.Reader, Errors) {
+	g.mu.Lock()
+	call, ok := g.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(detachedContext{ctx})
+		call = &inflightCall{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = call
+		go func() {
+			defer func() {
+				g.mu.Lock()
+				if g.calls[key] == call {
+					delete(g.calls, key)
+				}
+				g.mu.Unlock()
+				cancel()
+				close(call.done)
+			}()
+			call.data, call.resp, call.respBuf, call.errs = fn(callCtx)
+			// The response buffer is a nil *bytes.Reader unless the client is in debug mode.
+			if r, ok := call.respBuf.(*bytes.Reader); ok && r != nil {
+				call.respBody, _ = ioutil.ReadAll(r)
+			}
+		}()
+	}
+	call.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		g.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			// Nobody waits for the result, so cancel the request, and let new callers send another one.
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+			call.cancel()
+		}
+		g.mu.Unlock()
+		return nil, nil, nil, Errors{newError(ErrRequestError, ctx.Err())}
+	}
+	// Each caller reads the response buffer from its own reader.
+	respBuf := call.respBuf
+	if call.respBody != nil {
+		respBuf = bytes.NewReader(append([]byte(nil), call.respBody...))
+	}
+	// The errors are copied, since callers append to them.
+	return call.data, call.resp, respBuf, append(Errors(nil), call.errs...)
+}
+
+// detachedContext has the values of its parent, but is never canceled.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}               { return nil }
+func (detachedContext) Err() error                          { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
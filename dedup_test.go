@@ -0,0 +1,183 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zainirfan13/graphql-client"
+)
+
+func TestClient_WithDeduplication(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		if body := mustRead(req.Body); body == `{"query":"{viewer{login}}"}`+"\n" {
+			mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+		} else {
+			mustWrite(w, `{"data": {"addStar": {"id": "1"}}}`)
+		}
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithDeduplication(true)
+
+	const callers = 5
+	var wg sync.WaitGroup
+	logins := make([]string, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var q struct {
+				Viewer struct {
+					Login string
+				}
+			}
+			if err := client.Query(context.Background(), &q, nil); err != nil {
+				t.Error(err)
+			}
+			logins[i] = q.Viewer.Login
+		}(i)
+	}
+	waitFor(t, func() bool { return atomic.LoadInt32(&requests) >= 1 })
+	// Let the other callers join the request in flight.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+	for i, login := range logins {
+		if login != "gopher" {
+			t.Errorf("caller %d: got login %q, want gopher", i, login)
+		}
+	}
+
+	// A caller that gives up doesn't cancel the request of the others.
+	atomic.StoreInt32(&requests, 0)
+	release = make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	logins = make([]string, 2)
+	for i, ctx := range []context.Context{ctx, context.Background()} {
+		wg.Add(1)
+		go func(i int, ctx context.Context) {
+			defer wg.Done()
+			var q struct {
+				Viewer struct {
+					Login string
+				}
+			}
+			err := client.Query(ctx, &q, nil)
+			if i == 0 {
+				if err == nil {
+					t.Error("got no error for the canceled caller")
+				}
+			} else if err != nil {
+				t.Error(err)
+			}
+			logins[i] = q.Viewer.Login
+		}(i, ctx)
+	}
+	waitFor(t, func() bool { return atomic.LoadInt32(&requests) >= 1 })
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if got := atomic.LoadInt32(&requests); got != 1 || logins[1] != "gopher" {
+		t.Errorf("got %d requests and login %q, want 1 request and gopher", got, logins[1])
+	}
+
+	// Mutations aren't deduplicated, even when they overlap.
+	atomic.StoreInt32(&requests, 0)
+	release = make(chan struct{})
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var m struct {
+				AddStar struct {
+					ID string
+				} `graphql:"addStar(id: \"1\")"`
+			}
+			if err := client.Mutate(context.Background(), &m, nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	// Both mutations are in flight at once.
+	waitFor(t, func() bool { return atomic.LoadInt32(&requests) == 2 })
+	close(release)
+	wg.Wait()
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("got %d mutation requests, want 2", got)
+	}
+}
+
+func TestClient_WithDeduplication_debug(t *testing.T) {
+	const body = `{"data": {"viewer": {"login": 1}}}`
+	release := make(chan struct{})
+	var requests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, body)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithDeduplication(true).
+		WithDebug(true)
+
+	// Every caller gets the whole response body in the errors of debug mode.
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var q struct {
+				Viewer struct {
+					Login string
+				}
+			}
+			err := client.Query(context.Background(), &q, nil)
+			var errs graphql.Errors
+			if !errors.As(err, &errs) {
+				t.Errorf("got error: %v, want Errors", err)
+				return
+			}
+			internal, _ := errs[0].Extensions["internal"].(map[string]interface{})
+			response, _ := internal["response"].(map[string]interface{})
+			if got := response["body"]; got != body {
+				t.Errorf("got response body %q, want %q", got, body)
+			}
+		}()
+	}
+	waitFor(t, func() bool { return atomic.LoadInt32(&requests) >= 1 })
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+}
+
+// waitFor waits until cond is true, or fails the test after a second.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,519 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// astDocument is the subset of a GraphQL document needed to analyze, split and cache its operations.
+type astDocument struct {
+	// operation is the first operation of the document.
+	operation  *astOperation
+	operations []*astOperation
+	fragments  map[string][]*astSelection
+	// selectionSets holds the offsets of the selection sets of fields and fragments, after the opening brace.
+	selectionSets []int
+}
+
+type astOperation struct {
+	// name is the name of the operation, empty for anonymous operations.
+	name string
+	// header is the operation type and name up to the variable definitions or the selection set,
+	// e.g. "query Issues ", empty for the query shorthand.
+	header string
+	// definitions are the variable definitions, as written in the document.
+	definitions []astVariableDefinition
+	// defaults holds the default values of variables.
+	defaults map[string]astValue
+	// directives are the directives of the operation, as written in the document,
+	// and directiveVariables the names of the variables they use.
+	directives         string
+	directiveVariables []string
+	selections         []*astSelection
+}
+
+type astVariableDefinition struct {
+	name string
+	text string
+}
+
+// astSelection is a field, an inline fragment if name is empty, or a fragment spread if spread is set.
+type astSelection struct {
+	name  string
+	alias string
+	// typeCondition is the type condition of inline fragments, if any.
+	typeCondition string
+	spread        string
+	arguments     map[string]astValue
+	selections    []*astSelection
+	// text is the selection as written in the document.
+	text string
+	// variables are the names of the variables used by the selection, including its sub-selections.
+	variables []string
+}
+
+// responseName returns the key of the field in the response.
+func (s *astSelection) responseName() string {
+	if s.alias != "" {
+		return s.alias
+	}
+	return s.name
+}
+
+// astValue is an argument value.
+type astValue struct {
+	variable string
+	isInt    bool
+	n        int
+	// literal is the JSON encoding of scalar and enum values.
+	literal string
+	list    []astValue
+	isList  bool
+	fields  []astObjectField
+}
+
+type astObjectField struct {
+	name  string
+	value astValue
+}
+
+// canonical returns the JSON encoding of v, with the values of variables,
+// and the fields of objects sorted by name.
+func (v astValue) canonical(variables map[string]interface{}) string {
+	switch {
+	case v.variable != "":
+		value, ok := variables[v.variable]
+		if !ok {
+			return "null"
+		}
+		if raw, ok := value.(json.RawMessage); ok {
+			return string(raw)
+		}
+		b, err := json.Marshal(value)
+		if err != nil {
+			return "null"
+		}
+		return string(b)
+	case v.isList:
+		items := make([]string, len(v.list))
+		for i, item := range v.list {
+			items[i] = item.canonical(variables)
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	case v.fields != nil:
+		fields := append([]astObjectField(nil), v.fields...)
+		sort.Slice(fields, func(i, j int) bool { return fields[i].name < fields[j].name })
+		items := make([]string, len(fields))
+		for i, f := range fields {
+			items[i] = strconv.Quote(f.name) + ":" + f.value.canonical(variables)
+		}
+		return "{" + strings.Join(items, ",") + "}"
+	}
+	return v.literal
+}
+
+// parseDocument parses the operations and fragment definitions of a GraphQL document.
+func parseDocument(document string) (*astDocument, error) {
+	doc := &astDocument{fragments: make(map[string][]*astSelection)}
+	p := &parser{lexer: lexer{src: document}, doc: doc}
+	p.next()
+	for p.err == nil && p.tok.kind != tokenEOF {
+		switch {
+		case p.tok.is("{"):
+			doc.addOperation(&astOperation{selections: p.parseSelectionSet()})
+		case p.tok.kind == tokenName && p.tok.value == "fragment":
+			p.next()
+			name := p.expectName()
+			if p.expectName() != "on" {
+				p.fail("expected on")
+			}
+			p.expectName()
+			p.parseDirectives()
+			doc.selectionSets = append(doc.selectionSets, p.tok.offset+1)
+			doc.fragments[name] = p.parseSelectionSet()
+		case p.tok.kind == tokenName:
+			// query, mutation or subscription, with an optional name.
+			start := p.tok.offset
+			p.next()
+			name := ""
+			if p.tok.kind == tokenName {
+				name = p.tok.value
+				p.next()
+			}
+			op := &astOperation{name: name, header: p.lexer.src[start:p.tok.offset]}
+			p.parseVariableDefinitions(op)
+			directivesStart, used := p.tok.offset, len(p.used)
+			p.parseDirectives()
+			if p.err == nil && p.tok.offset > directivesStart {
+				op.directives = p.lexer.src[directivesStart:p.lastEnd]
+				op.directiveVariables = append([]string(nil), p.used[used:]...)
+			}
+			op.selections = p.parseSelectionSet()
+			doc.addOperation(op)
+		default:
+			p.fail("unexpected " + p.tok.String())
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return doc, nil
+}
+
+func (doc *astDocument) addOperation(op *astOperation) {
+	doc.operations = append(doc.operations, op)
+	if doc.operation == nil {
+		doc.operation = op
+	}
+}
+
+// operationNamed returns the operation with the name, or the only operation
+// of the document if name is empty, as the server selects it.
+func (doc *astDocument) operationNamed(name string) (*astOperation, error) {
+	if name == "" {
+		switch len(doc.operations) {
+		case 0:
+			return nil, fmt.Errorf("document has no operation")
+		case 1:
+			return doc.operation, nil
+		}
+		return nil, fmt.Errorf("document has %d operations, an operation name is required", len(doc.operations))
+	}
+	for _, op := range doc.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("operation %s is not defined", name)
+}
+
+type parser struct {
+	lexer lexer
+	doc   *astDocument
+	tok   token
+	err   error
+	// lastEnd is the offset of the end of the last consumed token.
+	lastEnd int
+	// used holds the names of the variables used so far.
+	used []string
+}
+
+func (p *parser) next() {
+	p.lastEnd = p.tok.offset + len(p.tok.value)
+	if p.err != nil {
+		p.tok = token{kind: tokenEOF}
+		return
+	}
+	p.tok, p.err = p.lexer.next()
+}
+
+func (p *parser) fail(msg string) {
+	if p.err == nil {
+		p.err = fmt.Errorf("syntax error at offset %d: %s", p.tok.offset, msg)
+	}
+	p.tok = token{kind: tokenEOF}
+}
+
+func (p *parser) expect(punct string) {
+	if !p.tok.is(punct) {
+		p.fail(fmt.Sprintf("expected %s, got %s", punct, p.tok))
+		return
+	}
+	p.next()
+}
+
+func (p *parser) expectName() string {
+	if p.tok.kind != tokenName {
+		p.fail("expected name, got " + p.tok.String())
+		return ""
+	}
+	name := p.tok.value
+	p.next()
+	return name
+}
+
+func (p *parser) parseVariableDefinitions(op *astOperation) {
+	op.defaults = make(map[string]astValue)
+	if !p.tok.is("(") {
+		return
+	}
+	p.next()
+	for p.err == nil && !p.tok.is(")") {
+		start := p.tok.offset
+		p.expect("$")
+		name := p.expectName()
+		p.expect(":")
+		p.parseType()
+		if p.tok.is("=") {
+			p.next()
+			op.defaults[name] = p.parseValue()
+		}
+		p.parseDirectives()
+		op.definitions = append(op.definitions, astVariableDefinition{name: name, text: p.lexer.src[start:p.lastEnd]})
+	}
+	p.expect(")")
+}
+
+func (p *parser) parseType() {
+	if p.tok.is("[") {
+		p.next()
+		p.parseType()
+		p.expect("]")
+	} else {
+		p.expectName()
+	}
+	if p.tok.is("!") {
+		p.next()
+	}
+}
+
+func (p *parser) parseSelectionSet() []*astSelection {
+	var selections []*astSelection
+	p.expect("{")
+	for p.err == nil && !p.tok.is("}") {
+		selections = append(selections, p.parseSelection())
+	}
+	p.expect("}")
+	return selections
+}
+
+func (p *parser) parseSelection() *astSelection {
+	start, used := p.tok.offset, len(p.used)
+	s := p.parseSelectionBody()
+	if p.err == nil {
+		s.text = p.lexer.src[start:p.lastEnd]
+		s.variables = append([]string(nil), p.used[used:]...)
+	}
+	return s
+}
+
+func (p *parser) parseSelectionBody() *astSelection {
+	s := &astSelection{}
+	if p.tok.is("...") {
+		p.next()
+		if p.tok.kind == tokenName && p.tok.value != "on" {
+			s.spread = p.expectName()
+			p.parseDirectives()
+			return s
+		}
+		if p.tok.kind == tokenName {
+			p.next()
+			s.typeCondition = p.expectName()
+		}
+		p.parseDirectives()
+		p.doc.selectionSets = append(p.doc.selectionSets, p.tok.offset+1)
+		s.selections = p.parseSelectionSet()
+		return s
+	}
+	s.name = p.expectName()
+	if p.tok.is(":") {
+		p.next()
+		s.alias, s.name = s.name, p.expectName()
+	}
+	s.arguments = p.parseArguments()
+	p.parseDirectives()
+	if p.tok.is("{") {
+		p.doc.selectionSets = append(p.doc.selectionSets, p.tok.offset+1)
+		s.selections = p.parseSelectionSet()
+	}
+	return s
+}
+
+func (p *parser) parseArguments() map[string]astValue {
+	if !p.tok.is("(") {
+		return nil
+	}
+	p.next()
+	args := make(map[string]astValue)
+	for p.err == nil && !p.tok.is(")") {
+		name := p.expectName()
+		p.expect(":")
+		args[name] = p.parseValue()
+	}
+	p.expect(")")
+	return args
+}
+
+func (p *parser) parseDirectives() {
+	for p.err == nil && p.tok.is("@") {
+		p.next()
+		p.expectName()
+		p.parseArguments()
+	}
+}
+
+func (p *parser) parseValue() astValue {
+	switch {
+	case p.tok.is("$"):
+		p.next()
+		name := p.expectName()
+		p.used = append(p.used, name)
+		return astValue{variable: name}
+	case p.tok.is("["):
+		p.next()
+		v := astValue{isList: true}
+		for p.err == nil && !p.tok.is("]") {
+			v.list = append(v.list, p.parseValue())
+		}
+		p.expect("]")
+		return v
+	case p.tok.is("{"):
+		p.next()
+		v := astValue{fields: []astObjectField{}}
+		for p.err == nil && !p.tok.is("}") {
+			name := p.expectName()
+			p.expect(":")
+			v.fields = append(v.fields, astObjectField{name: name, value: p.parseValue()})
+		}
+		p.expect("}")
+		return v
+	case p.tok.kind == tokenInt:
+		n, err := strconv.Atoi(p.tok.value)
+		v := astValue{isInt: err == nil, n: n, literal: p.tok.value}
+		p.next()
+		return v
+	case p.tok.kind == tokenFloat:
+		v := astValue{literal: p.tok.value}
+		p.next()
+		return v
+	case p.tok.kind == tokenString:
+		v := astValue{literal: p.tok.value}
+		if strings.HasPrefix(v.literal, `"""`) {
+			v.literal = strconv.Quote(v.literal[3 : len(v.literal)-3])
+		}
+		p.next()
+		return v
+	case p.tok.kind == tokenName:
+		v := astValue{literal: p.tok.value}
+		if v.literal != "true" && v.literal != "false" && v.literal != "null" {
+			// Enum value.
+			v.literal = strconv.Quote(v.literal)
+		}
+		p.next()
+		return v
+	default:
+		p.fail("expected value, got " + p.tok.String())
+	}
+	return astValue{}
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind   tokenKind
+	value  string
+	offset int
+}
+
+func (t token) is(punct string) bool {
+	return t.kind == tokenPunct && t.value == punct
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of document"
+	}
+	return strconv.Quote(t.value)
+}
+
+// lexer splits a GraphQL document into tokens, skipping whitespace, commas and comments.
+type lexer struct {
+	src string
+	pos int
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.pos++
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return l.scan()
+		}
+	}
+	return token{kind: tokenEOF, offset: l.pos}, nil
+}
+
+func (l *lexer) scan() (token, error) {
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		l.pos += 3
+		return token{kind: tokenPunct, value: "...", offset: start}, nil
+	case strings.IndexByte("!$&()/:=@[]{|}", c) >= 0:
+		l.pos++
+		return token{kind: tokenPunct, value: string(c), offset: start}, nil
+	case c == '_' || isLetter(c):
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokenName, value: l.src[start:l.pos], offset: start}, nil
+	case c == '-' || isDigit(c):
+		kind := tokenInt
+		l.pos++
+		for l.pos < len(l.src) {
+			c := l.src[l.pos]
+			if c == '.' || c == 'e' || c == 'E' || ((c == '+' || c == '-') && (l.src[l.pos-1] == 'e' || l.src[l.pos-1] == 'E')) {
+				kind = tokenFloat
+			} else if !isDigit(c) {
+				break
+			}
+			l.pos++
+		}
+		return token{kind: kind, value: l.src[start:l.pos], offset: start}, nil
+	case strings.HasPrefix(l.src[l.pos:], `"""`):
+		end := strings.Index(l.src[l.pos+3:], `"""`)
+		for end >= 0 && l.src[l.pos+3+end-1] == '\\' {
+			next := strings.Index(l.src[l.pos+3+end+3:], `"""`)
+			if next < 0 {
+				end = -1
+				break
+			}
+			end += 3 + next
+		}
+		if end < 0 {
+			return token{}, fmt.Errorf("syntax error at offset %d: unterminated string", start)
+		}
+		l.pos += 3 + end + 3
+		return token{kind: tokenString, value: l.src[start:l.pos], offset: start}, nil
+	case c == '"':
+		l.pos++
+		for l.pos < len(l.src) && l.src[l.pos] != '"' && l.src[l.pos] != '\n' {
+			if l.src[l.pos] == '\\' {
+				l.pos++
+			}
+			l.pos++
+		}
+		if l.pos >= len(l.src) || l.src[l.pos] != '"' {
+			return token{}, fmt.Errorf("syntax error at offset %d: unterminated string", start)
+		}
+		l.pos++
+		return token{kind: tokenString, value: l.src[start:l.pos], offset: start}, nil
+	}
+	return token{}, fmt.Errorf("syntax error at offset %d: unexpected character %q", start, c)
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,282 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EndpointResolver resolves the endpoints of a GraphQL server, e.g. the replicas of a gateway.
+type EndpointResolver interface {
+	// Resolve returns the endpoint URLs, in order of preference.
+	Resolve(ctx context.Context) ([]string, error)
+}
+
+// StaticEndpoints is a fixed list of endpoints, resolved in order,
+// i.e. the first endpoint is the primary and the others are fallbacks.
+type StaticEndpoints []string
+
+// Resolve returns the endpoints.
+func (e StaticEndpoints) Resolve(ctx context.Context) ([]string, error) {
+	return append([]string(nil), e...), nil
+}
+
+// RoundRobinEndpoints is a fixed list of endpoints, resolved starting from the next endpoint each time,
+// to spread the requests across them.
+type RoundRobinEndpoints struct {
+	endpoints []string
+	next      uint32
+}
+
+// NewRoundRobinEndpoints creates a round-robin resolver of the endpoints.
+func NewRoundRobinEndpoints(endpoints ...string) *RoundRobinEndpoints {
+	return &RoundRobinEndpoints{endpoints: endpoints}
+}
+
+// Resolve returns the endpoints, rotated by one from the previous call.
+func (e *RoundRobinEndpoints) Resolve(ctx context.Context) ([]string, error) {
+	n := len(e.endpoints)
+	if n == 0 {
+		return nil, nil
+	}
+	start := int((atomic.AddUint32(&e.next, 1) - 1) % uint32(n))
+	return append(append([]string(nil), e.endpoints[start:]...), e.endpoints[:start]...), nil
+}
+
+// SRVEndpoints resolves the endpoints from the DNS SRV records of a service,
+// in the order of their priority, randomized by weight. It must be used by pointer.
+type SRVEndpoints struct {
+	// Service, Proto and Name are looked up as the _service._proto.name record.
+	// If Service and Proto are empty, Name is looked up directly.
+	Service string
+	Proto   string
+	Name    string
+	// Scheme is the scheme of the endpoint URLs, https if empty.
+	Scheme string
+	// Path is the path of the endpoint URLs, e.g. /graphql.
+	Path string
+	// TTL is the time the records are reused before they are looked up again, 30s if zero.
+	TTL time.Duration
+	// Resolver looks up the records, net.DefaultResolver if nil.
+	Resolver *net.Resolver
+
+	mu        sync.Mutex
+	endpoints []string
+	expires   time.Time
+}
+
+// Resolve returns the endpoints of the SRV records, looked up at most once per TTL.
+func (e *SRVEndpoints) Resolve(ctx context.Context) ([]string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.endpoints) > 0 && time.Now().Before(e.expires) {
+		return append([]string(nil), e.endpoints...), nil
+	}
+
+	resolver := e.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	_, records, err := resolver.LookupSRV(ctx, e.Service, e.Proto, e.Name)
+	if err != nil {
+		return nil, fmt.Errorf("problem looking up SRV records of %s: %w", e.Name, err)
+	}
+	e.endpoints = srvEndpointURLs(records, e.Scheme, e.Path)
+	ttl := e.TTL
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+	e.expires = time.Now().Add(ttl)
+	return append([]string(nil), e.endpoints...), nil
+}
+
+// srvEndpointURLs returns the URLs of the targets of the SRV records.
+func srvEndpointURLs(records []*net.SRV, scheme string, path string) []string {
+	if scheme == "" {
+		scheme = "https"
+	}
+	endpoints := make([]string, 0, len(records))
+	for _, record := range records {
+		host := record.Target
+		if len(host) > 1 && host[len(host)-1] == '.' {
+			host = host[:len(host)-1]
+		}
+		u := url.URL{Scheme: scheme, Host: net.JoinHostPort(host, strconv.Itoa(int(record.Port))), Path: path}
+		endpoints = append(endpoints, u.String())
+	}
+	return endpoints
+}
+
+// Failover configures the endpoints of a client, and how it fails over between them.
+type Failover struct {
+	// Resolver resolves the endpoints.
+	Resolver EndpointResolver
+	// Cooldown is the time an endpoint is considered unhealthy after a connection error
+	// or a 5xx response, 30s if zero.
+	Cooldown time.Duration
+}
+
+// WithFailover returns a copy of the client that sends requests to the endpoints of the resolver
+// instead of its URL. Requests go to the first healthy endpoint, and fail over to the next endpoints
+// in turn on connection errors or 5xx responses, which mark the endpoint unhealthy for the cooldown.
+// Unhealthy endpoints are tried last, and the URL of the client is used if the resolver returns no endpoint.
+//
+// Mutations only fail over when the request wasn't sent, i.e. on dial errors and open circuits,
+// since an endpoint may fail after running the mutation, e.g. with a 502 Bad Gateway response.
+func (c *Client) WithFailover(failover Failover) *Client {
+	if failover.Cooldown <= 0 {
+		failover.Cooldown = 30 * time.Second
+	}
+	nc := *c
+	nc.endpoints = &endpointPool{failover: failover, unhealthy: make(map[string]time.Time)}
+	return &nc
+}
+
+// sendHTTP sends req, hedged if the client hedges it.
+func (c *Client) sendHTTP(req *http.Request) (*http.Response, error) {
+	if c.hedger != nil && req.Context().Value(hedgeableKey{}) != nil {
+		return c.hedger.do(c, req)
+	}
+	return c.sendLimited(req, true)
+}
+
+// sendLimited sends req through the rate limiter of the client if any,
+// failing over between the endpoints of the client if any and failover is true.
+// Each endpoint that is tried waits for its turn.
+func (c *Client) sendLimited(req *http.Request, failover bool) (*http.Response, error) {
+	send := c.sendEndpoint
+	if c.rateLimiter != nil {
+		send = func(req *http.Request) (*http.Response, error) {
+			return c.rateLimiter.do(c.sendEndpoint, req)
+		}
+	}
+	if failover && c.endpoints != nil {
+		return c.endpoints.do(send, req)
+	}
+	return send(req)
+}
+
+// endpointPool tracks the health of the endpoints of a client.
+type endpointPool struct {
+	failover  Failover
+	mu        sync.Mutex
+	unhealthy map[string]time.Time
+}
+
+// order returns the healthy endpoints first, then the unhealthy endpoints, each in their order.
+func (p *endpointPool) order(endpoints []string) []string {
+	now := time.Now()
+	healthy := make([]string, 0, len(endpoints))
+	var unhealthy []string
+	p.mu.Lock()
+	for _, endpoint := range endpoints {
+		if until, ok := p.unhealthy[endpoint]; ok && now.Before(until) {
+			unhealthy = append(unhealthy, endpoint)
+			continue
+		}
+		delete(p.unhealthy, endpoint)
+		healthy = append(healthy, endpoint)
+	}
+	p.mu.Unlock()
+	return append(healthy, unhealthy...)
+}
+
+func (p *endpointPool) setHealthy(endpoint string, healthy bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if healthy {
+		delete(p.unhealthy, endpoint)
+	} else {
+		p.unhealthy[endpoint] = time.Now().Add(p.failover.Cooldown)
+	}
+}
+
+// do sends req to the endpoints in turn, until one of them replies without a server error.
+// Requests that can't be sent again, i.e. of mutations, only fail over if they weren't sent.
+func (p *endpointPool) do(send func(*http.Request) (*http.Response, error), req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	endpoints, err := p.failover.Resolver.Resolve(ctx)
+	if err != nil || len(endpoints) == 0 {
+		return send(req)
+	}
+	endpoints = p.order(endpoints)
+	idempotent := isIdempotent(req)
+
+	for i := 0; ; i++ {
+		endpoint := endpoints[i]
+		r, err := endpointRequest(req, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := send(r)
+		if err == nil && resp.StatusCode < http.StatusInternalServerError {
+			p.setHealthy(endpoint, true)
+			return resp, nil
+		}
+		if ctx.Err() != nil || isSigningError(err) {
+			return resp, err
+		}
+		p.setHealthy(endpoint, false)
+		if i == len(endpoints)-1 || !(idempotent || (err != nil && isNotSentError(err))) {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+	}
+}
+
+// idempotentKey is the context key of the requests of queries, which can be sent again.
+type idempotentKey struct{}
+
+// withIdempotent returns ctx, marked to send its requests again if needed.
+func withIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+// isIdempotent reports whether req can be sent again, i.e. it's a GET request or the request of queries.
+func isIdempotent(req *http.Request) bool {
+	return req.Method == http.MethodGet || req.Context().Value(idempotentKey{}) != nil
+}
+
+// isNotSentError reports whether err is an error of a request that wasn't sent,
+// i.e. a DNS or dial error, or an open circuit.
+func isNotSentError(err error) bool {
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	var open *CircuitOpenError
+	return errors.As(err, &dnsErr) || (errors.As(err, &opErr) && opErr.Op == "dial") || errors.As(err, &open)
+}
+
+// endpointRequest returns a copy of req sent to endpoint. The query and variables of GET requests are kept.
+func endpointRequest(req *http.Request, endpoint string) (*http.Request, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("problem parsing endpoint %q: %w", endpoint, err)
+	}
+	if req.Method == http.MethodGet {
+		params, reqParams := u.Query(), req.URL.Query()
+		for _, name := range []string{"query", "variables"} {
+			if values, ok := reqParams[name]; ok {
+				params[name] = values
+			}
+		}
+		u.RawQuery = params.Encode()
+	}
+	r := req.Clone(req.Context())
+	r.URL = u
+	r.Host = ""
+	if req.GetBody != nil {
+		if r.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
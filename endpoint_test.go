@@ -0,0 +1,204 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient_WithFailover(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		hosts []string
+	)
+	client := NewClient("http://unused/graphql", &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		hosts = append(hosts, req.URL.Host)
+		mu.Unlock()
+		switch req.URL.Host {
+		case "a":
+			return nil, errors.New("connection refused")
+		case "b":
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable", Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+		}
+		body, _ := ioutil.ReadAll(req.Body)
+		if got, want := string(body), `{"query":"{viewer{login}}"}`+"\n"; got != want {
+			t.Errorf("got body %q, want %q", got, want)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(`{"data": {"viewer": {"login": "gopher"}}}`))}, nil
+	})}).WithFailover(Failover{Resolver: StaticEndpoints{"http://a/graphql", "http://b/graphql", "http://c/graphql"}})
+
+	var q struct {
+		Viewer struct {
+			Login string
+		}
+	}
+	for i, want := range [][]string{{"a", "b", "c"}, {"c"}} {
+		hosts = nil
+		q.Viewer.Login = ""
+		if err := client.Query(context.Background(), &q, nil); err != nil {
+			t.Fatalf("query %d: got error: %v", i, err)
+		}
+		if q.Viewer.Login != "gopher" {
+			t.Errorf("query %d: got login %q", i, q.Viewer.Login)
+		}
+		if !reflect.DeepEqual(hosts, want) {
+			t.Errorf("query %d: got hosts %v, want %v", i, hosts, want)
+		}
+	}
+}
+
+func TestClient_WithFailover_mutation(t *testing.T) {
+	var hosts []string
+	client := NewClient("http://unused/graphql", &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		hosts = append(hosts, req.URL.Host)
+		switch req.URL.Host {
+		case "a":
+			return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+		case "b":
+			return &http.Response{StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway", Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+		case "c":
+			return nil, errors.New("connection reset by peer")
+		}
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(`{"data": {"addStar": {"id": "1"}}}`))}, nil
+	})})
+
+	var m struct {
+		AddStar struct {
+			ID string
+		} `graphql:"addStar(id: \"1\")"`
+	}
+	tests := []struct {
+		endpoints StaticEndpoints
+		wantHosts []string
+		wantErr   bool
+	}{
+		// Dial errors fail over, since the mutation wasn't sent.
+		{endpoints: StaticEndpoints{"http://a/graphql", "http://d/graphql"}, wantHosts: []string{"a", "d"}},
+		// Server errors and errors after the mutation was sent don't.
+		{endpoints: StaticEndpoints{"http://b/graphql", "http://d/graphql"}, wantHosts: []string{"b"}, wantErr: true},
+		{endpoints: StaticEndpoints{"http://c/graphql", "http://d/graphql"}, wantHosts: []string{"c"}, wantErr: true},
+	}
+	for _, tc := range tests {
+		hosts = nil
+		err := client.WithFailover(Failover{Resolver: tc.endpoints}).Mutate(context.Background(), &m, nil)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%v: got error %v, want error %v", tc.endpoints, err, tc.wantErr)
+		}
+		if !reflect.DeepEqual(hosts, tc.wantHosts) {
+			t.Errorf("%v: got hosts %v, want %v", tc.endpoints, hosts, tc.wantHosts)
+		}
+	}
+}
+
+func TestClient_WithFailover_rateLimit(t *testing.T) {
+	client := NewClient("http://unused/graphql", &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host == "a" {
+			return nil, errors.New("connection refused")
+		}
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(`{"data": {"viewer": {"login": "gopher"}}}`))}, nil
+	})}).
+		WithFailover(Failover{Resolver: StaticEndpoints{"http://a/graphql", "http://b/graphql"}}).
+		WithRateLimit(RateLimit{Rate: 0.001, Burst: 2})
+
+	var q struct {
+		Viewer struct {
+			Login string
+		}
+	}
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Both endpoints took a token, so the bucket is empty.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := client.Query(ctx, &q, nil); err == nil {
+		t.Error("got no error, want the request to wait for a token")
+	}
+}
+
+func TestClient_WithFailover_allUnhealthy(t *testing.T) {
+	client := NewClient("http://unused/graphql", &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway", Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader("down"))}, nil
+	})}).WithFailover(Failover{Resolver: StaticEndpoints{"http://a/graphql", "http://b/graphql"}})
+
+	var q struct {
+		Viewer struct {
+			Login string
+		}
+	}
+	err := client.Query(context.Background(), &q, nil)
+	if err == nil || !strings.Contains(err.Error(), `502 Bad Gateway; body: "down"`) {
+		t.Errorf("got error %v, want the response of the last endpoint", err)
+	}
+}
+
+func TestRoundRobinEndpoints(t *testing.T) {
+	resolver := NewRoundRobinEndpoints("a", "b", "c")
+	for _, want := range [][]string{{"a", "b", "c"}, {"b", "c", "a"}, {"c", "a", "b"}, {"a", "b", "c"}} {
+		got, err := resolver.Resolve(context.Background())
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, %v, want %v", got, err, want)
+		}
+	}
+}
+
+func TestSRVEndpointURLs(t *testing.T) {
+	got := srvEndpointURLs([]*net.SRV{
+		{Target: "gw1.example.com.", Port: 443},
+		{Target: "gw2.example.com.", Port: 8443},
+	}, "", "/graphql")
+	want := []string{"https://gw1.example.com:443/graphql", "https://gw2.example.com:8443/graphql"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+type endpointTestConn struct{}
+
+func (endpointTestConn) ReadJSON(v interface{}) error  { return nil }
+func (endpointTestConn) WriteJSON(v interface{}) error { return nil }
+func (endpointTestConn) Close() error                  { return nil }
+func (endpointTestConn) SetReadLimit(limit int64)      {}
+
+func TestSubscriptionClient_WithEndpointResolver(t *testing.T) {
+	down := map[string]bool{"ws://a/graphql": true}
+	var dialed []string
+	sc := NewSubscriptionClient("ws://unused/graphql").
+		WithEndpointResolver(StaticEndpoints{"ws://a/graphql", "ws://b/graphql", "ws://c/graphql"}).
+		WithWebSocket(func(sc *SubscriptionClient) (WebsocketConn, error) {
+			dialed = append(dialed, sc.GetURL())
+			if down[sc.GetURL()] {
+				return nil, errors.New("connection refused")
+			}
+			return endpointTestConn{}, nil
+		})
+
+	if err := sc.init(); err != nil {
+		t.Fatal(err)
+	}
+	// The client reconnects to the same endpoint, even though the first endpoint is up again.
+	delete(down, "ws://a/graphql")
+	sc.conn = nil
+	if err := sc.init(); err != nil {
+		t.Fatal(err)
+	}
+	// It moves to the next endpoint when its endpoint is down.
+	down["ws://b/graphql"] = true
+	sc.conn = nil
+	if err := sc.init(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"ws://a/graphql", "ws://b/graphql", "ws://b/graphql", "ws://b/graphql", "ws://c/graphql"}
+	if !reflect.DeepEqual(dialed, want) {
+		t.Errorf("got dialed %v, want %v", dialed, want)
+	}
+}
@@ -0,0 +1,96 @@
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// GraphQLEnum interface is implemented by Go types that represent a GraphQL enum.
+// EnumValues returns the values allowed by the schema.
+//
+// Variables of enum types are validated before the request is sent,
+// including enum fields of input objects and items of lists.
+// When decoding responses, unknown values are rejected, unless the type
+// implements the GraphQLEnumFallback interface as well.
+//
+// Like GetGraphQLType, EnumValues is applied to the zero value of the type,
+// so its output should be a constant.
+type GraphQLEnum interface {
+	EnumValues() []string
+}
+
+// GraphQLEnumFallback interface is implemented by enum types that map unknown
+// values in responses to a fallback value instead of failing the decoding.
+// It is useful to stay compatible with servers that add new enum values.
+//
+// The fallback value needn't be one of EnumValues: e.g. the enums generated by
+// enumgen fall back to the empty string, which marks unknown values. Such a value
+// is rejected like any other unknown value when it's sent as a variable.
+type GraphQLEnumFallback interface {
+	GraphQLEnum
+	EnumFallback() string
+}
+
+// validateVariables checks that the values of enum variables are allowed by their types.
+func validateVariables(variables map[string]interface{}) error {
+	for name, value := range variables {
+		if err := validateEnumValue(reflect.ValueOf(value)); err != nil {
+			return fmt.Errorf("invalid variable $%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// validateEnumValue recursively checks the enum values inside v.
+func validateEnumValue(v reflect.Value) error {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Type() == omittableType {
+		return validateEnumValue(reflect.ValueOf(v.Interface().(Omittable).Value()))
+	}
+	if v.Kind() == reflect.String && v.Type().Implements(graphqlEnumInterface) {
+		enum := v.Interface().(GraphQLEnum)
+		s := v.String()
+		for _, value := range enum.EnumValues() {
+			if s == value {
+				return nil
+			}
+		}
+		return fmt.Errorf("value %q is not allowed by enum %v", s, v.Type())
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return validateEnumValue(v.Elem())
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := validateEnumValue(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := validateEnumValue(iter.Value()); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				// Skip unexported field.
+				continue
+			}
+			if err := validateEnumValue(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var graphqlEnumInterface = reflect.TypeOf((*GraphQLEnum)(nil)).Elem()
@@ -0,0 +1,56 @@
+package graphql
+
+import (
+	"testing"
+)
+
+// Color is an enum type for testing.
+type Color string
+
+const (
+	ColorRed  Color = "RED"
+	ColorBlue Color = "BLUE"
+)
+
+func (Color) GetGraphQLType() string { return "Color" }
+
+func (Color) EnumValues() []string { return []string{"RED", "BLUE"} }
+
+type PaintInput struct {
+	Colors  []Color `json:"colors"`
+	Primary *Color  `json:"primary,omitempty"`
+}
+
+func TestConstructQuery_enumVariables(t *testing.T) {
+	var q struct {
+		Paint struct {
+			ID string
+		} `graphql:"paint(color: $color, input: $input)"`
+	}
+	blue := ColorBlue
+
+	got, err := ConstructQuery(&q, map[string]interface{}{
+		"color": ColorRed,
+		"input": PaintInput{Colors: []Color{ColorRed, ColorBlue}, Primary: &blue},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `query ($color:Color!$input:PaintInput!){paint(color: $color, input: $input){id}}`; got != want {
+		t.Errorf("\ngot:  %q\nwant: %q", got, want)
+	}
+
+	invalid := Color("GREEN")
+	tests := []map[string]interface{}{
+		{"color": Color("GREEN")},
+		{"color": &invalid},
+		{"input": PaintInput{Colors: []Color{ColorRed, "GREEN"}}},
+		{"input": &PaintInput{Primary: &invalid}},
+	}
+	for i, variables := range tests {
+		_, err := ConstructQuery(&q, variables)
+		if err == nil {
+			t.Errorf("test case %d: got error: nil, want: non-nil", i)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+// Package enumgen generates Go types for the enums of a GraphQL schema.
+// The generated types implement the GraphQLType and GraphQLEnum interfaces
+// of the graphql package, so that variables are declared with the right
+// type name and their values are validated.
+package enumgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"github.com/zainirfan13/graphql-client/ident"
+)
+
+// Options configures the generated code.
+type Options struct {
+	// Package is the name of the package of the generated file. Required.
+	Package string
+	// Namer converts GraphQL names to Go identifiers.
+	// If nil, the default initialisms and brands of the ident package are used.
+	Namer *ident.Namer
+	// Fallback adds an EnumFallback method to every enum, which maps
+	// unknown values in responses to the zero value instead of failing.
+	Fallback bool
+}
+
+// Generate returns formatted Go source that declares a type for each enum of schema.
+// schema is either the schema definition language or the JSON result of an introspection query.
+// Introspection enums, whose names start with "__", are skipped.
+// It fails if several enums or values convert to the same Go identifier.
+func Generate(schema []byte, opts Options) ([]byte, error) {
+	if opts.Package == "" {
+		return nil, fmt.Errorf("package name is required")
+	}
+	if opts.Namer == nil {
+		opts.Namer = ident.NewNamer()
+	}
+
+	introspection := schema
+	if trimmed := bytes.TrimSpace(schema); len(trimmed) == 0 || trimmed[0] != '{' {
+		s, err := graphqlgo.ParseSchema(string(schema), nil, graphqlgo.UseStringDescriptions())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse schema: %w", err)
+		}
+		introspection, err = s.ToJSON()
+		if err != nil {
+			return nil, fmt.Errorf("failed to introspect schema: %w", err)
+		}
+	}
+
+	enums, err := parseIntrospection(introspection)
+	if err != nil {
+		return nil, err
+	}
+
+	data := struct {
+		Package  string
+		Fallback bool
+		Enums    []enumType
+	}{
+		Package:  opts.Package,
+		Fallback: opts.Fallback,
+	}
+	// declared maps the identifiers of the generated file to the GraphQL names they were converted from,
+	// since names that differ only in case or separators, e.g. ORDER_BY and order_by, convert to the same one.
+	declared := make(map[string]string)
+	declare := func(goName, name string) error {
+		if other, ok := declared[goName]; ok {
+			return fmt.Errorf("%s and %s both convert to the Go identifier %s", other, name, goName)
+		}
+		declared[goName] = name
+		return nil
+	}
+	for _, t := range enums {
+		e := enumType{
+			Name:        t.Name,
+			GoName:      goName(opts.Namer, t.Name),
+			Description: t.Description,
+		}
+		if err := declare(e.GoName, t.Name); err != nil {
+			return nil, err
+		}
+		for _, v := range t.EnumValues {
+			value := enumValue{
+				Name:        v.Name,
+				GoName:      e.GoName + goName(opts.Namer, v.Name),
+				Description: v.Description,
+				Deprecated:  v.IsDeprecated,
+			}
+			if err := declare(value.GoName, t.Name+"."+v.Name); err != nil {
+				return nil, err
+			}
+			e.Values = append(e.Values, value)
+		}
+		data.Enums = append(data.Enums, e)
+	}
+
+	var buf bytes.Buffer
+	if err := enumTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return src, nil
+}
+
+type introspectionType struct {
+	Kind        string
+	Name        string
+	Description string
+	EnumValues  []struct {
+		Name         string
+		Description  string
+		IsDeprecated bool
+	}
+}
+
+// parseIntrospection returns the enum types of an introspection result, sorted by name.
+// It accepts both the full response and its data object.
+func parseIntrospection(data []byte) ([]introspectionType, error) {
+	var out struct {
+		Data *struct {
+			Schema struct {
+				Types []introspectionType
+			} `json:"__schema"`
+		}
+		Schema struct {
+			Types []introspectionType
+		} `json:"__schema"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode introspection result: %w", err)
+	}
+	types := out.Schema.Types
+	if out.Data != nil {
+		types = out.Data.Schema.Types
+	}
+
+	var enums []introspectionType
+	for _, t := range types {
+		if t.Kind != "ENUM" || strings.HasPrefix(t.Name, "__") {
+			continue
+		}
+		enums = append(enums, t)
+	}
+	sort.Slice(enums, func(i, j int) bool { return enums[i].Name < enums[j].Name })
+	return enums, nil
+}
+
+// goName converts a GraphQL name, in any of the common conventions, to MixedCaps.
+//
+// E.g., "THUMBS_UP" -> "ThumbsUp", "order_by" -> "OrderBy", "IssueState" -> "IssueState".
+func goName(namer *ident.Namer, name string) string {
+	if strings.Contains(name, "_") || strings.ToUpper(name) == name {
+		return namer.ToMixedCaps(ident.ParseSnakeCase(strings.ToLower(name)))
+	}
+	return namer.ToMixedCaps(namer.ParseMixedCaps(name))
+}
+
+type enumType struct {
+	Name        string
+	GoName      string
+	Description string
+	Values      []enumValue
+}
+
+type enumValue struct {
+	Name        string
+	GoName      string
+	Description string
+	Deprecated  bool
+}
+
+var enumTemplate = template.Must(template.New("").Funcs(template.FuncMap{
+	"comment": func(s string) string {
+		return strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n// ")
+	},
+}).Parse(`// Code generated by enumgen; DO NOT EDIT.
+
+package {{.Package}}
+{{range .Enums}}{{$enum := .}}
+// {{.GoName}} represents the {{.Name}} GraphQL enum.
+{{- if .Description}}
+//
+// {{comment .Description}}
+{{- end}}
+type {{.GoName}} string
+
+// Values of {{.GoName}}.
+const (
+{{- range .Values}}
+	{{if .Description}}// {{comment .Description}}
+	{{end}}{{if .Deprecated}}// Deprecated: {{.GoName}} is deprecated in the schema.
+	{{end}}{{.GoName}} {{$enum.GoName}} = {{printf "%q" .Name}}
+{{- end}}
+)
+
+// GetGraphQLType returns the GraphQL type name of {{.GoName}}.
+func ({{.GoName}}) GetGraphQLType() string { return {{printf "%q" .Name}} }
+
+// EnumValues returns the values of {{.GoName}} allowed by the schema.
+func ({{.GoName}}) EnumValues() []string {
+	return []string{ {{- range $i, $v := .Values}}{{if $i}}, {{end}}{{printf "%q" $v.Name}}{{end -}} }
+}
+{{if $.Fallback}}
+// EnumFallback maps unknown values of {{.GoName}} in responses to the empty value.
+func ({{.GoName}}) EnumFallback() string { return "" }
+{{end}}{{end}}`))
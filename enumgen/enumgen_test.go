@@ -0,0 +1,126 @@
+package enumgen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zainirfan13/graphql-client/enumgen"
+)
+
+const schema = `
+"""
+The possible states of an issue.
+"""
+enum IssueState {
+	"An issue that is still open."
+	OPEN
+	CLOSED @deprecated(reason: "use DONE")
+	DONE
+}
+
+enum order_by {
+	asc
+	asc_nulls_first
+}
+
+type Query {
+	issueState: IssueState
+	orderBy: order_by
+}
+`
+
+func TestGenerate(t *testing.T) {
+	src, err := enumgen.Generate([]byte(schema), enumgen.Options{Package: "github", Fallback: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(src)
+	for _, want := range []string{
+		"// Code generated by enumgen; DO NOT EDIT.\n\npackage github\n",
+		"// IssueState represents the IssueState GraphQL enum.\n//\n// The possible states of an issue.\ntype IssueState string\n",
+		"\t// An issue that is still open.\n\tIssueStateOpen IssueState = \"OPEN\"\n",
+		"\t// Deprecated: IssueStateClosed is deprecated in the schema.\n\tIssueStateClosed IssueState = \"CLOSED\"\n",
+		`func (IssueState) EnumValues() []string {` + "\n\t" + `return []string{"OPEN", "CLOSED", "DONE"}`,
+		`func (IssueState) EnumFallback() string { return "" }`,
+		`OrderByAscNullsFirst OrderBy = "asc_nulls_first"`,
+		`func (OrderBy) GetGraphQLType() string { return "order_by" }`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("generated code doesn't contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "__TypeKind") {
+		t.Errorf("generated code contains introspection enums:\n%s", got)
+	}
+}
+
+func TestGenerate_introspection(t *testing.T) {
+	introspection := `{
+		"data": {
+			"__schema": {
+				"types": [
+					{"kind": "OBJECT", "name": "Query"},
+					{"kind": "ENUM", "name": "ReactionContent", "enumValues": [
+						{"name": "THUMBS_UP", "description": "Represents the :+1: emoji."},
+						{"name": "THUMBS_DOWN"}
+					]}
+				]
+			}
+		}
+	}`
+	src, err := enumgen.Generate([]byte(introspection), enumgen.Options{Package: "github"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(src)
+	for _, want := range []string{
+		"\t// Represents the :+1: emoji.\n\tReactionContentThumbsUp ",
+		`ReactionContentThumbsDown ReactionContent = "THUMBS_DOWN"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("generated code doesn't contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "EnumFallback") {
+		t.Errorf("generated code contains EnumFallback:\n%s", got)
+	}
+}
+
+func TestGenerate_invalidSchema(t *testing.T) {
+	_, err := enumgen.Generate([]byte(`enum {`), enumgen.Options{Package: "github"})
+	if err == nil {
+		t.Error("got error: nil, want: non-nil")
+	}
+}
+
+func TestGenerate_collision(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		want   string
+	}{
+		{
+			name:   "values",
+			schema: `enum Sort { ORDER_BY order_by } type Query { sort: Sort }`,
+			want:   "Sort.ORDER_BY and Sort.order_by both convert to the Go identifier SortOrderBy",
+		},
+		{
+			name:   "enums",
+			schema: `enum Foo { A } enum FOO { B } type Query { a: Foo, b: FOO }`,
+			want:   "FOO and Foo both convert to the Go identifier Foo",
+		},
+		{
+			name:   "enum and value",
+			schema: `enum Color { RED_X } enum ColorRedX { Y } type Query { a: Color, b: ColorRedX }`,
+			want:   "Color.RED_X and ColorRedX both convert to the Go identifier ColorRedX",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := enumgen.Generate([]byte(tc.schema), enumgen.Options{Package: "github"})
+			if err == nil || err.Error() != tc.want {
+				t.Errorf("got error: %v, want: %s", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FanOutErrors holds the errors of a fan-out query by index of the argument sets.
+// Items are nil for the argument sets whose field was resolved without errors.
+type FanOutErrors []error
+
+// Error implements error interface.
+func (e FanOutErrors) Error() string {
+	b := strings.Builder{}
+	for i, err := range e {
+		if err == nil {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("; ")
+		}
+		fmt.Fprintf(&b, "argument set %d: %v", i, err)
+	}
+	return b.String()
+}
+
+// QueryFanOut queries field once for each set of arguments in a single request,
+// e.g. to fetch many users by id, and stores the results into results,
+// which must be a pointer to a slice whose element type is the selection of field.
+// The results are in the order of args.
+//
+// Each field is aliased by its index, e.g. _0 and _1, and its arguments are
+// passed as variables named by the alias and the argument, e.g. $_0_id.
+// Argument values follow the same rules as variables, so Var can be used to
+// declare their types.
+//
+//	var users []struct{ Login string }
+//	err := client.QueryFanOut(ctx, &users, "user", []map[string]interface{}{{"id": 1}, {"id": 2}})
+//	// query ($_0_id:Int!$_1_id:Int!){_0:user(id:$_0_id){login},_1:user(id:$_1_id){login}}
+//
+// GraphQL errors are attributed to the argument sets by their path.
+// If all errors could be attributed, the error is a FanOutErrors,
+// and the results of the other argument sets are valid.
+func (c *Client) QueryFanOut(ctx context.Context, results interface{}, field string, args []map[string]interface{}, options ...Option) error {
+	rv := reflect.ValueOf(results)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("results must be a non-nil pointer to slice, got %T", results)
+	}
+	if len(args) == 0 {
+		rv.Elem().Set(reflect.MakeSlice(rv.Elem().Type(), 0, 0))
+		return nil
+	}
+
+	list := reflect.MakeSlice(rv.Elem().Type(), len(args), len(args))
+	elemIsPtr := list.Type().Elem().Kind() == reflect.Ptr
+	q := NewSelection()
+	variables := make(map[string]interface{})
+	for i, set := range args {
+		alias := fanOutAlias(i)
+		dest := list.Index(i).Addr().Interface()
+		if elemIsPtr {
+			list.Index(i).Set(reflect.New(list.Type().Elem().Elem()))
+			dest = list.Index(i).Interface()
+		}
+
+		names := make([]string, 0, len(set))
+		for name := range set {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fieldArgs := make(map[string]interface{}, len(set))
+		for _, name := range names {
+			variable := alias + "_" + name
+			variables[variable] = set[name]
+			fieldArgs[name] = Variable(variable)
+		}
+		q.Field(field, dest).Alias(alias)
+		if len(fieldArgs) > 0 {
+			q.Args(fieldArgs)
+		}
+	}
+
+	data, resp, respBuf, errs := c.buildAndRequest(ctx, queryOperation, q, variables, options...)
+	if err := c.processResponse(q, data, resp, respBuf, errs, options); err != nil {
+		errs = err.(Errors)
+	}
+	if elemIsPtr && len(data) > 0 {
+		// Keep nil pointers for null fields.
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err == nil {
+			for i := range args {
+				if raw, ok := fields[fanOutAlias(i)]; ok && string(raw) == "null" {
+					list.Index(i).Set(reflect.Zero(list.Type().Elem()))
+				}
+			}
+		}
+	}
+	rv.Elem().Set(list)
+
+	if len(errs) == 0 {
+		return nil
+	}
+	perSet := make(FanOutErrors, len(args))
+	for _, err := range errs {
+		i, ok := fanOutIndex(err.Path, len(args))
+		if !ok {
+			return errs
+		}
+		if perSet[i] == nil {
+			perSet[i] = Errors{err}
+		} else {
+			perSet[i] = append(perSet[i].(Errors), err)
+		}
+	}
+	return perSet
+}
+
+// fanOutAlias returns the alias of the field for the argument set at index i.
+func fanOutAlias(i int) string {
+	return "_" + strconv.Itoa(i)
+}
+
+// fanOutIndex returns the index of the argument set of the error path, if any.
+func fanOutIndex(path []interface{}, n int) (int, bool) {
+	if len(path) == 0 {
+		return 0, false
+	}
+	alias, ok := path[0].(string)
+	if !ok || !strings.HasPrefix(alias, "_") {
+		return 0, false
+	}
+	i, err := strconv.Atoi(alias[1:])
+	if err != nil || i < 0 || i >= n || fanOutAlias(i) != alias {
+		return 0, false
+	}
+	return i, true
+}
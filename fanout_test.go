@@ -0,0 +1,66 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/zainirfan13/graphql-client"
+)
+
+func TestClient_QueryFanOut(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body := mustRead(req.Body)
+		if got, want := body, `{"query":"query ($_0_id:ID!$_1_id:ID!$_2_id:ID!){_0:user(id:$_0_id){login},_1:user(id:$_1_id){login},_2:user(id:$_2_id){login}}","variables":{"_0_id":"1","_1_id":"2","_2_id":"3"}}`+"\n"; got != want {
+			t.Errorf("got body: %v, want %v", got, want)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{
+			"data": {"_0": {"login": "gopher"}, "_1": null, "_2": {"login": "octocat"}},
+			"errors": [{"message": "user 2 not found", "path": ["_1"]}]
+		}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	var users []*struct{ Login string }
+	err := client.QueryFanOut(context.Background(), &users, "user", []map[string]interface{}{
+		{"id": graphql.ID("1")},
+		{"id": graphql.ID("2")},
+		{"id": graphql.ID("3")},
+	})
+	var fanOutErrs graphql.FanOutErrors
+	if !errors.As(err, &fanOutErrs) {
+		t.Fatalf("got error: %v, want FanOutErrors", err)
+	}
+	if len(fanOutErrs) != 3 || fanOutErrs[0] != nil || fanOutErrs[1] == nil || fanOutErrs[2] != nil {
+		t.Fatalf("got errors: %#v, want an error for index 1", fanOutErrs)
+	}
+	if got, want := err.Error(), "argument set 1: Message: user 2 not found, Locations: []"; got != want {
+		t.Errorf("got error: %q, want: %q", got, want)
+	}
+	if len(users) != 3 || users[0] == nil || users[0].Login != "gopher" || users[1] != nil || users[2] == nil || users[2].Login != "octocat" {
+		t.Errorf("got users: %v", users)
+	}
+}
+
+func TestClient_QueryFanOut_unattributedErrors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": null, "errors": [{"message": "rate limited"}]}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	var users []struct{ Login string }
+	err := client.QueryFanOut(context.Background(), &users, "user", []map[string]interface{}{{"id": 1}})
+	var errs graphql.Errors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Message != "rate limited" {
+		t.Errorf("got error: %v, want Errors", err)
+	}
+
+	if err := client.QueryFanOut(context.Background(), users, "user", nil); err == nil {
+		t.Error("got error: nil, want error for non-pointer results")
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"reflect"
 	"strings"
 
 	"github.com/zainirfan13/graphql-client/internal/jsonutil"
@@ -24,6 +26,38 @@ type Client struct {
 	httpClient      *http.Client
 	requestModifier RequestModifier
 	debug           bool
+	namingStrategy  NamingStrategy
+	scalarTypes     ScalarTypes
+	// complexityBudget, if set, limits the complexity of the operations.
+	complexityBudget *ComplexityBudget
+	// querySplitting, if set, splits the queries over its budget.
+	querySplitting *QuerySplitting
+	// cache, if set, is the normalized cache of operations.
+	cache *CacheConfig
+	// cacheRefreshes holds the queries being refreshed in the background for the cache.
+	cacheRefreshes *cacheRefreshes
+	// getQueries sends queries as GET requests.
+	getQueries bool
+	// httpCache, if set, stores the responses of GET requests.
+	httpCache CacheStore
+	// inflight, if set, deduplicates identical queries in flight.
+	inflight *inflightGroup
+	// batcher, if set, batches the queries sent within a time window.
+	batcher *batcher
+	// endpoints, if set, are the endpoints that requests fail over between.
+	endpoints *endpointPool
+	// breakers, if set, are the circuit breakers of the endpoints.
+	breakers *circuitBreakers
+	// rateLimiter, if set, limits the rate and the concurrency of the requests.
+	rateLimiter *rateLimiter
+	// hedger, if set, hedges the queries.
+	hedger *hedger
+	// shadow, if set, is the shadow backend of the queries.
+	shadow *shadowBackend
+	// tokens, if set, caches the tokens that authorize the requests.
+	tokens *tokenCache
+	// signer, if set, signs each HTTP request before it's sent.
+	signer RequestSigner
 }
 
 // NewClient creates a GraphQL client targeting the specified GraphQL server URL.
@@ -97,8 +131,12 @@ func (c *Client) NamedMutateRaw(ctx context.Context, name string, m interface{},
 
 // buildAndRequest the common method that builds and send graphql request
 func (c *Client) buildAndRequest(ctx context.Context, op operationType, v interface{}, variables map[string]interface{}, options ...Option) ([]byte, *http.Response, io.Reader, Errors) {
+	variables, options, err := withOptionVariables(variables, options, c.namingStrategy)
+	if err != nil {
+		return nil, nil, nil, Errors{newError(ErrGraphQLEncode, err)}
+	}
 	var query string
-	var err error
+	options = c.withDefaultOptions(options)
 	switch op {
 	case queryOperation:
 		query, err = ConstructQuery(v, variables, options...)
@@ -110,11 +148,43 @@ func (c *Client) buildAndRequest(ctx context.Context, op operationType, v interf
 		return nil, nil, nil, Errors{newError(ErrGraphQLEncode, err)}
 	}
 
+	if c.cache != nil {
+		return c.cacheRequest(ctx, op, query, variables, options...)
+	}
+	return c.send(ctx, op, query, variables, options...)
+}
+
+// send sends the operation query, split into several requests if needed.
+func (c *Client) send(ctx context.Context, op operationType, query string, variables map[string]interface{}, options ...Option) ([]byte, *http.Response, io.Reader, Errors) {
+	if op == queryOperation && c.querySplitting != nil {
+		return c.splitRequest(ctx, query, variables, options...)
+	}
 	return c.request(ctx, query, variables, options...)
 }
 
 // Request the common method that send graphql request
 func (c *Client) request(ctx context.Context, query string, variables map[string]interface{}, options ...Option) ([]byte, *http.Response, io.Reader, Errors) {
+	if c.shadow != nil && isQueryDocument(query) {
+		return c.shadow.request(c, ctx, query, variables, options...)
+	}
+	return c.requestWithoutShadow(ctx, query, variables, options...)
+}
+
+// requestWithoutShadow sends the graphql request to the backend of the client only.
+func (c *Client) requestWithoutShadow(ctx context.Context, query string, variables map[string]interface{}, options ...Option) ([]byte, *http.Response, io.Reader, Errors) {
+	variables, err := requestVariables(variables)
+	if err != nil {
+		return nil, nil, nil, Errors{newError(ErrGraphQLEncode, err)}
+	}
+	if c.complexityBudget != nil {
+		complexity, err := operationComplexity(query, operationNameOf(options), variables, c.complexityBudget.Model)
+		if err == nil {
+			err = c.complexityBudget.check(complexity)
+		}
+		if err != nil {
+			return nil, nil, nil, Errors{newError(ErrComplexityLimit, err)}
+		}
+	}
 	in := struct {
 		Query     string                 `json:"query"`
 		Variables map[string]interface{} `json:"variables,omitempty"`
@@ -122,14 +192,25 @@ func (c *Client) request(ctx context.Context, query string, variables map[string
 		Query:     query,
 		Variables: variables,
 	}
+	if c.batcher != nil && !c.getQueries && isQueryDocument(query) {
+		return c.batch(ctx, in.Query, in.Variables)
+	}
 	var buf bytes.Buffer
-	err := json.NewEncoder(&buf).Encode(in)
+	err = json.NewEncoder(&buf).Encode(in)
 	if err != nil {
 		return nil, nil, nil, Errors{newError(ErrGraphQLEncode, err)}
 	}
 
 	reqReader := bytes.NewReader(buf.Bytes())
-	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, reqReader)
+	if isQueryDocument(query) {
+		ctx = withIdempotent(c.withHedgeable(ctx))
+	}
+	var request *http.Request
+	if c.getQueries && isQueryDocument(query) {
+		request, err = newGETRequest(ctx, c.url, query, variables)
+	} else {
+		request, err = http.NewRequestWithContext(ctx, http.MethodPost, c.url, reqReader)
+	}
 	if err != nil {
 		e := newError(ErrRequestError, fmt.Errorf("problem constructing request: %w", err))
 		if c.debug {
@@ -137,20 +218,32 @@ func (c *Client) request(ctx context.Context, query string, variables map[string
 		}
 		return nil, nil, nil, Errors{e}
 	}
-	request.Header.Add("Content-Type", "application/json")
+	if request.Method == http.MethodPost {
+		request.Header.Add("Content-Type", "application/json")
+	}
 
 	if c.requestModifier != nil {
 		c.requestModifier(request)
 	}
 
-	resp, err := c.httpClient.Do(request)
+	if c.inflight != nil && isQueryDocument(query) {
+		return c.inflight.do(ctx, requestKey(request, buf.Bytes()), func(ctx context.Context) ([]byte, *http.Response, io.Reader, Errors) {
+			return c.sendRequest(request.WithContext(ctx), reqReader)
+		})
+	}
+	return c.sendRequest(request, reqReader)
+}
+
+// sendRequest sends the GraphQL request, and decodes the data and errors of its response.
+func (c *Client) sendRequest(request *http.Request, reqReader *bytes.Reader) ([]byte, *http.Response, io.Reader, Errors) {
+	resp, err := c.authorizedRoundTrip(request)
 
 	if c.debug {
 		reqReader.Seek(0, io.SeekStart)
 	}
 
 	if err != nil {
-		e := newError(ErrRequestError, err)
+		e := requestError(err)
 		if c.debug {
 			e = e.withRequest(request, reqReader)
 		}
@@ -241,19 +334,27 @@ func (c *Client) doRaw(ctx context.Context, op operationType, v interface{}, var
 // do executes a single GraphQL operation and unmarshal json.
 func (c *Client) do(ctx context.Context, op operationType, v interface{}, variables map[string]interface{}, options ...Option) error {
 	data, resp, respBuf, errs := c.buildAndRequest(ctx, op, v, variables, options...)
-	return c.processResponse(v, data, resp, respBuf, errs)
+	return c.processResponse(v, data, resp, respBuf, errs, options)
 }
 
 // Executes a pre-built query and unmarshals the response into v. Unlike the Query method you have to specify in the query the
 // fields that you want to receive as they are not inferred from v. This method is useful if you need to build the query dynamically.
 func (c *Client) Exec(ctx context.Context, query string, v interface{}, variables map[string]interface{}, options ...Option) error {
+	variables, options, err := withOptionVariables(variables, options, c.namingStrategy)
+	if err != nil {
+		return Errors{newError(ErrGraphQLEncode, err)}
+	}
 	data, resp, respBuf, errs := c.request(ctx, query, variables, options...)
-	return c.processResponse(v, data, resp, respBuf, errs)
+	return c.processResponse(v, data, resp, respBuf, errs, options)
 }
 
 // Executes a pre-built query and returns the raw json message. Unlike the Query method you have to specify in the query the
 // fields that you want to receive as they are not inferred from the interface. This method is useful if you need to build the query dynamically.
 func (c *Client) ExecRaw(ctx context.Context, query string, variables map[string]interface{}, options ...Option) ([]byte, error) {
+	variables, options, err := withOptionVariables(variables, options, c.namingStrategy)
+	if err != nil {
+		return nil, Errors{newError(ErrGraphQLEncode, err)}
+	}
 	data, _, _, errs := c.request(ctx, query, variables, options...)
 	if len(errs) > 0 {
 		return data, errs
@@ -261,9 +362,9 @@ func (c *Client) ExecRaw(ctx context.Context, query string, variables map[string
 	return data, nil
 }
 
-func (c *Client) processResponse(v interface{}, data []byte, resp *http.Response, respBuf io.Reader, errs Errors) error {
+func (c *Client) processResponse(v interface{}, data []byte, resp *http.Response, respBuf io.Reader, errs Errors, options []Option) error {
 	if len(data) > 0 {
-		err := jsonutil.UnmarshalGraphQL(data, v)
+		err := jsonutil.UnmarshalGraphQLWithOptions(data, v, c.unmarshalOptions(options))
 		if err != nil {
 			we := newError(ErrGraphQLDecode, err)
 			if c.debug {
@@ -280,25 +381,70 @@ func (c *Client) processResponse(v interface{}, data []byte, resp *http.Response
 	return nil
 }
 
+// withDefaultOptions prepends the options configured on the client,
+// so that the options of the operation take precedence over them
+func (c *Client) withDefaultOptions(options []Option) []Option {
+	var defaults []Option
+	if c.namingStrategy != nil {
+		defaults = append(defaults, FieldNaming(c.namingStrategy))
+	}
+	if len(c.scalarTypes) > 0 {
+		// The types of the client are a private copy, so they needn't be copied again.
+		defaults = append(defaults, scalarTypesOption{c.scalarTypes})
+	}
+	if len(defaults) == 0 {
+		return options
+	}
+	return append(defaults, options...)
+}
+
+// unmarshalOptions returns the decoding options of the client, overridden by the operation options
+func (c *Client) unmarshalOptions(options []Option) jsonutil.Options {
+	naming := c.namingStrategy
+	scalarTypes := scalarTypeLayers{c.scalarTypes}
+	for _, option := range options {
+		switch o := option.(type) {
+		case fieldNamingOption:
+			naming = o.strategy
+		case scalarTypesOption:
+			scalarTypes = append(scalarTypes, o.types)
+		}
+	}
+	opts := jsonutil.Options{
+		FieldName: naming,
+	}
+	if len(scalarTypes) > 1 || len(c.scalarTypes) > 0 {
+		opts.IsScalar = func(t reflect.Type) bool {
+			_, ok := scalarTypes.lookup(t)
+			return ok
+		}
+	}
+	return opts
+}
+
 // Returns a copy of the client with the request modifier set. This allows you to reuse the same
 // TCP connection for multiple slightly different requests to the same server
 // (i.e. different authentication headers for multitenant applications)
 func (c *Client) WithRequestModifier(f RequestModifier) *Client {
-	return &Client{
-		url:             c.url,
-		httpClient:      c.httpClient,
-		requestModifier: f,
-	}
+	nc := *c
+	nc.requestModifier = f
+	return &nc
 }
 
 // WithDebug enable debug mode to print internal error detail
 func (c *Client) WithDebug(debug bool) *Client {
-	return &Client{
-		url:             c.url,
-		httpClient:      c.httpClient,
-		requestModifier: c.requestModifier,
-		debug:           debug,
-	}
+	nc := *c
+	nc.debug = debug
+	return &nc
+}
+
+// WithNamingStrategy returns a copy of the client that names untagged struct fields
+// with the provided strategy, both in queries and when decoding responses.
+// By default, field names are converted to lowerCamelCase.
+func (c *Client) WithNamingStrategy(strategy NamingStrategy) *Client {
+	nc := *c
+	nc.namingStrategy = strategy
+	return &nc
 }
 
 // errors represents the "errors" array in a response from a GraphQL server.
@@ -314,6 +460,10 @@ type Error struct {
 		Line   int `json:"line"`
 		Column int `json:"column"`
 	} `json:"locations"`
+	// Path is the path of the response field that failed, made of field names and list indices.
+	Path []interface{} `json:"path,omitempty"`
+	// err is the error the client failed with, if the error isn't from the response.
+	err error
 }
 
 // Error implements error interface.
@@ -330,6 +480,32 @@ func (e Errors) Error() string {
 	return b.String()
 }
 
+// Unwrap returns the error the client failed with, e.g. a *ComplexityError,
+// or nil if the error is from the response.
+func (e Error) Unwrap() error {
+	return e.err
+}
+
+// As finds the first error in the errors that matches target, for errors.As.
+func (e Errors) As(target interface{}) bool {
+	for _, err := range e {
+		if err.err != nil && errors.As(err.err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Is reports whether any of the errors matches target, for errors.Is.
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if err.err != nil && errors.Is(err.err, target) {
+			return true
+		}
+	}
+	return false
+}
+
 func (e Error) getInternalExtension() map[string]interface{} {
 	if e.Extensions == nil {
 		return make(map[string]interface{})
@@ -348,6 +524,7 @@ func newError(code string, err error) Error {
 		Extensions: map[string]interface{}{
 			"code": code,
 		},
+		err: err,
 	}
 }
 
@@ -371,6 +548,10 @@ func (e Error) withRequest(req *http.Request, bodyReader io.Reader) Error {
 }
 
 func (e Error) withResponse(res *http.Response, bodyReader io.Reader) Error {
+	if res == nil || bodyReader == nil {
+		// The response was read from a cache, or shared by a batch.
+		return e
+	}
 	internal := e.getInternalExtension()
 	bodyBytes, err := ioutil.ReadAll(bodyReader)
 	if err != nil {
@@ -408,4 +589,19 @@ const (
 	ErrJsonDecode    = "json_decode_error"
 	ErrGraphQLEncode = "graphql_encode_error"
 	ErrGraphQLDecode = "graphql_decode_error"
+	// ErrComplexityLimit is the code of the error of operations over the complexity budget of the client.
+	ErrComplexityLimit = "complexity_limit_error"
+	// ErrCacheMiss is the code of the error of cache-only queries whose result isn't cached.
+	ErrCacheMiss = "cache_miss_error"
+	// ErrCircuitOpen is the code of the error of requests to an endpoint whose circuit breaker is open.
+	ErrCircuitOpen = "circuit_open_error"
 )
+
+// WithScalarTypes returns a copy of the client that registers the scalar types
+// for every operation, in addition to the scalar types already registered.
+// See ScalarTypes.
+func (c *Client) WithScalarTypes(types ScalarTypes) *Client {
+	nc := *c
+	nc.scalarTypes = c.scalarTypes.merge(types)
+	return &nc
+}
@@ -8,7 +8,10 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/zainirfan13/graphql-client"
 )
@@ -282,6 +285,186 @@ func TestClient_Query_emptyVariables(t *testing.T) {
 	}
 }
 
+// Test that the naming strategy of the client applies to the query and the decoded response.
+func TestClient_Query_namingStrategy(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body := mustRead(req.Body)
+		if got, want := body, `{"query":"{user_by_pk{first_name,database_id}}"}`+"\n"; got != want {
+			t.Errorf("got body: %v, want %v", got, want)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"user_by_pk": {"first_name": "Gopher", "database_id": 1}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithNamingStrategy(graphql.SnakeCaseNaming)
+
+	var q struct {
+		UserByPk struct {
+			FirstName  string
+			DatabaseID int
+		}
+	}
+	err := client.Query(context.Background(), &q, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.UserByPk.FirstName, "Gopher"; got != want {
+		t.Errorf("got q.UserByPk.FirstName: %q, want: %q", got, want)
+	}
+	if got, want := q.UserByPk.DatabaseID, 1; got != want {
+		t.Errorf("got q.UserByPk.DatabaseID: %d, want: %d", got, want)
+	}
+}
+
+// point is a custom scalar that is encoded as a JSON object.
+type point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+func TestClient_Query_scalarTypes(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body := mustRead(req.Body)
+		if got, want := body, `{"query":"query ($near:Point!$since:DateTime){shapes(near: $near, since: $since){center,vertices,createdAt}}","variables":{"near":{"x":1,"y":2},"since":null}}`+"\n"; got != want {
+			t.Errorf("got body: %v, want %v", got, want)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"shapes": [{"center": {"x": 1, "y": 2}, "vertices": [{"x": 0, "y": 0}], "createdAt": "2022-03-04T05:06:07Z"}]}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithScalarTypes(graphql.ScalarTypes{}.Register(point{}, "Point"))
+
+	var q struct {
+		Shapes []struct {
+			Center    point
+			Vertices  []point
+			CreatedAt time.Time
+		} `graphql:"shapes(near: $near, since: $since)"`
+	}
+	variables := map[string]interface{}{
+		"near":  point{X: 1, Y: 2},
+		"since": (*time.Time)(nil),
+	}
+	err := client.Query(context.Background(), &q, variables, graphql.Scalars(graphql.ScalarTypes{}.Register(time.Time{}, "DateTime")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Shapes) != 1 {
+		t.Fatalf("got %d shapes, want 1", len(q.Shapes))
+	}
+	if got, want := q.Shapes[0].Center, (point{X: 1, Y: 2}); got != want {
+		t.Errorf("got q.Shapes[0].Center: %v, want: %v", got, want)
+	}
+	if got, want := q.Shapes[0].Vertices, []point{{}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got q.Shapes[0].Vertices: %v, want: %v", got, want)
+	}
+}
+
+func TestClient_Query_vars(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body := mustRead(req.Body)
+		if got, want := body, `{"query":"query ($after:String$first:Int=10$login:String){user(login: $login){repositories(first: $first, after: $after){totalCount}}}","variables":{"login":"gopher"}}`+"\n"; got != want {
+			t.Errorf("got body: %v, want %v", got, want)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"user": {"repositories": {"totalCount": 42}}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	var q struct {
+		User struct {
+			Repositories struct {
+				TotalCount int
+			} `graphql:"repositories(first: $first, after: $after)"`
+		} `graphql:"user(login: $login)"`
+	}
+	variables := map[string]interface{}{
+		"login": graphql.Var{Value: "gopher", Type: "String"},
+		"first": graphql.Var{Type: "Int", Default: 10},
+		"after": graphql.Var{Type: "String"},
+	}
+	err := client.Query(context.Background(), &q, variables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.User.Repositories.TotalCount, 42; got != want {
+		t.Errorf("got q.User.Repositories.TotalCount: %d, want: %d", got, want)
+	}
+}
+
+func TestClient_Query_structVariables(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body := mustRead(req.Body)
+		if got, want := body, `{"query":"query ($first:Int$login:String!){user(login: $login){repositories(first: $first){totalCount}}}","variables":{"first":10,"login":"gopher"}}`+"\n"; got != want {
+			t.Errorf("got body: %v, want %v", got, want)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"user": {"repositories": {"totalCount": 42}}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	var q struct {
+		User struct {
+			Repositories struct {
+				TotalCount int
+			} `graphql:"repositories(first: $first)"`
+		} `graphql:"user(login: $login)"`
+	}
+	variables := struct {
+		Login string `graphql:"login"`
+		First int    `type:"Int"`
+	}{Login: "gopher", First: 10}
+	err := client.Query(context.Background(), &q, nil, graphql.Variables(variables))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.User.Repositories.TotalCount, 42; got != want {
+		t.Errorf("got q.User.Repositories.TotalCount: %d, want: %d", got, want)
+	}
+
+	err = client.Query(context.Background(), &q, map[string]interface{}{"login": "gopher"}, graphql.Variables(variables))
+	if err == nil || !strings.Contains(err.Error(), "duplicate variable $login") {
+		t.Errorf("got error: %v, want duplicate variable", err)
+	}
+}
+
+func TestClient_Query_structVariablesNaming(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body := mustRead(req.Body)
+		if got, want := body, `{"query":"query ($first_count:Int!$owner:String!){repository(owner: $owner){issues(first: $first_count){total_count}}}","variables":{"first_count":10,"owner":"gopher"}}`+"\n"; got != want {
+			t.Errorf("got body: %v, want %v", got, want)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"repository": {"issues": {"total_count": 42}}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithNamingStrategy(graphql.SnakeCaseNaming)
+
+	var q struct {
+		Repository struct {
+			Issues struct {
+				TotalCount int
+			} `graphql:"issues(first: $first_count)"`
+		} `graphql:"repository(owner: $owner)"`
+	}
+	variables := struct {
+		// The graphql tag overrides the naming strategy of the client.
+		Login      string `graphql:"owner"`
+		FirstCount int
+	}{Login: "gopher", FirstCount: 10}
+	if err := client.Query(context.Background(), &q, nil, graphql.Variables(variables)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.Repository.Issues.TotalCount, 42; got != want {
+		t.Errorf("got q.Repository.Issues.TotalCount: %d, want: %d", got, want)
+	}
+}
+
 // Test ignored field
 // handled no differently than a nil variables map.
 func TestClient_Query_ignoreFields(t *testing.T) {
@@ -0,0 +1,202 @@
+package graphql
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Hedging configures the hedging of queries.
+type Hedging struct {
+	// Delay is the time to wait for a response before sending a hedge, 100ms if zero.
+	Delay time.Duration
+	// MaxHedges is the maximum number of hedges of a request, 1 if zero.
+	MaxHedges int
+	// MaxPerSecond caps the number of hedges per second across the requests of the client,
+	// to avoid amplifying the load of a slow server. Unlimited if zero.
+	MaxPerSecond float64
+	// Endpoints, if set, resolves the endpoints of the hedges: the first hedge is sent to the first endpoint,
+	// and so on. Otherwise hedges are sent like the request, e.g. failing over between the endpoints of the client.
+	Endpoints EndpointResolver
+}
+
+// WithHedging returns a copy of the client that hedges its queries: if no response arrives within the delay,
+// it sends a duplicate request, and so on up to the maximum number of hedges. A hedge is sent right away
+// if every request sent so far failed. The first successful response, i.e. without network error or 5xx status,
+// is returned, and the other requests are canceled. Mutations are never hedged.
+func (c *Client) WithHedging(hedging Hedging) *Client {
+	if hedging.Delay <= 0 {
+		hedging.Delay = 100 * time.Millisecond
+	}
+	if hedging.MaxHedges <= 0 {
+		hedging.MaxHedges = 1
+	}
+	h := &hedger{config: hedging}
+	if hedging.MaxPerSecond > 0 {
+		h.capacity = math.Max(1, math.Ceil(hedging.MaxPerSecond))
+		h.tokens, h.last = h.capacity, time.Now()
+	}
+	nc := *c
+	nc.hedger = h
+	return &nc
+}
+
+// hedgeableKey is the context key of the requests that may be hedged.
+type hedgeableKey struct{}
+
+// withHedgeable returns ctx, marked to hedge its requests if the client hedges queries.
+func (c *Client) withHedgeable(ctx context.Context) context.Context {
+	if c.hedger == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, hedgeableKey{}, true)
+}
+
+// hedger sends the hedges of requests, and caps their rate.
+type hedger struct {
+	config   Hedging
+	mu       sync.Mutex
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+// allow reports whether a hedge may be sent, and takes its token.
+func (h *hedger) allow() bool {
+	if h.config.MaxPerSecond <= 0 {
+		return true
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	h.tokens = math.Min(h.capacity, h.tokens+now.Sub(h.last).Seconds()*h.config.MaxPerSecond)
+	h.last = now
+	if h.tokens < 1 {
+		return false
+	}
+	h.tokens--
+	return true
+}
+
+// do sends req, and its hedges until a request succeeds.
+func (h *hedger) do(c *Client, req *http.Request) (*http.Response, error) {
+	type result struct {
+		resp    *http.Response
+		err     error
+		attempt int
+	}
+	ctx := req.Context()
+	results := make(chan result, h.config.MaxHedges+1)
+	var cancels []context.CancelFunc
+	var endpoints []string
+	send := func(attempt int) {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		cancels = append(cancels, cancel)
+		endpoint := ""
+		if attempt > 0 && len(endpoints) > 0 {
+			endpoint = endpoints[(attempt-1)%len(endpoints)]
+		}
+		go func() {
+			resp, err := h.send(c, req.WithContext(attemptCtx), endpoint)
+			results <- result{resp, err, attempt}
+		}()
+	}
+
+	// hedge sends the next hedge, if the request may have more hedges.
+	hedge := func() bool {
+		if len(cancels) > h.config.MaxHedges || ctx.Err() != nil || !h.allow() {
+			return false
+		}
+		if len(cancels) == 1 && h.config.Endpoints != nil {
+			endpoints, _ = h.config.Endpoints.Resolve(ctx)
+		}
+		send(len(cancels))
+		return true
+	}
+
+	send(0)
+	pending, timer := 1, time.NewTimer(h.config.Delay)
+	defer timer.Stop()
+	// last is the last failed attempt, which is returned if every attempt fails.
+	// The context of the attempts that failed before it is canceled.
+	var last *result
+	for pending > 0 {
+		select {
+		case r := <-results:
+			pending--
+			if r.err == nil && r.resp.StatusCode < http.StatusInternalServerError {
+				for i, cancel := range cancels {
+					if i != r.attempt {
+						cancel()
+					}
+				}
+				// The other requests are drained in the background.
+				go func(pending int) {
+					for ; pending > 0; pending-- {
+						if r := <-results; r.resp != nil {
+							r.resp.Body.Close()
+						}
+					}
+				}(pending)
+				// The context of the request is canceled once its body is closed.
+				r.resp.Body = &releaseBody{ReadCloser: r.resp.Body, release: cancels[r.attempt]}
+				return r.resp, nil
+			}
+			if last != nil {
+				if last.resp != nil {
+					last.resp.Body.Close()
+				}
+				cancels[last.attempt]()
+			}
+			last = &r
+			if pending == 0 && hedge() {
+				// Every request failed before the delay, so hedge right away.
+				pending++
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(h.config.Delay)
+			}
+		case <-timer.C:
+			if len(cancels) > h.config.MaxHedges || ctx.Err() != nil {
+				continue
+			}
+			if hedge() {
+				pending++
+			}
+			if len(cancels) <= h.config.MaxHedges {
+				timer.Reset(h.config.Delay)
+			}
+		}
+	}
+	if last.resp == nil {
+		cancels[last.attempt]()
+	} else {
+		last.resp.Body = &releaseBody{ReadCloser: last.resp.Body, release: cancels[last.attempt]}
+	}
+	return last.resp, last.err
+}
+
+// send sends an attempt of a request, to endpoint if any.
+func (h *hedger) send(c *Client, req *http.Request, endpoint string) (*http.Response, error) {
+	if endpoint == "" {
+		r := req.Clone(req.Context())
+		if req.GetBody != nil {
+			var err error
+			if r.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		return c.sendLimited(r, true)
+	}
+	r, err := endpointRequest(req, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return c.sendLimited(r, false)
+}
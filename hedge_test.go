@@ -0,0 +1,168 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zainirfan13/graphql-client"
+)
+
+func TestClient_WithHedging(t *testing.T) {
+	var requests, canceled int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if req.URL.Host == "slow" {
+			select {
+			case <-req.Context().Done():
+				atomic.AddInt32(&canceled, 1)
+				return
+			case <-time.After(300 * time.Millisecond):
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if req.Method == http.MethodPost && strings.HasPrefix(mustRead(req.Body), `{"query":"mutation`) {
+			mustWrite(w, `{"data": {"addStar": {"id": "1"}}}`)
+			return
+		}
+		mustWrite(w, `{"data": {"viewer": {"login": "`+req.URL.Host+`"}}}`)
+	})
+	client := graphql.NewClient("http://slow/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithHedging(graphql.Hedging{
+			Delay:        20 * time.Millisecond,
+			MaxPerSecond: 1,
+			Endpoints:    graphql.StaticEndpoints{"http://fast/graphql"},
+		})
+
+	query := func() (string, time.Duration) {
+		var q struct {
+			Viewer struct {
+				Login string
+			}
+		}
+		start := time.Now()
+		if err := client.Query(context.Background(), &q, nil); err != nil {
+			t.Fatal(err)
+		}
+		return q.Viewer.Login, time.Since(start)
+	}
+
+	// The hedge to the fast endpoint replies first, and the slow request is canceled.
+	login, elapsed := query()
+	if login != "fast" || elapsed >= 300*time.Millisecond {
+		t.Errorf("got response of %s after %v, want the hedge", login, elapsed)
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if got := atomic.LoadInt32(&canceled); got != 1 {
+		t.Errorf("got %d canceled requests, want 1", got)
+	}
+
+	// Hedges are capped at 1 per second.
+	atomic.StoreInt32(&requests, 0)
+	if login, _ := query(); login != "slow" {
+		t.Errorf("got response of %s, want no hedge", login)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+
+	// Mutations are never hedged.
+	time.Sleep(time.Second)
+	atomic.StoreInt32(&requests, 0)
+	var m struct {
+		AddStar struct {
+			ID string
+		} `graphql:"addStar(id: \"1\")"`
+	}
+	if err := client.Mutate(context.Background(), &m, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+}
+
+// failingTransport fails every request, and records their contexts.
+type failingTransport struct {
+	mu       sync.Mutex
+	contexts []context.Context
+}
+
+func (t *failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.contexts = append(t.contexts, req.Context())
+	t.mu.Unlock()
+	return nil, errors.New("connection refused")
+}
+
+func TestClient_WithHedging_cancelFailed(t *testing.T) {
+	transport := &failingTransport{}
+	client := graphql.NewClient("/graphql", &http.Client{Transport: transport}).
+		WithHedging(graphql.Hedging{Delay: time.Second, MaxHedges: 2})
+
+	var q struct {
+		Viewer struct {
+			Login string
+		}
+	}
+	if err := client.Query(context.Background(), &q, nil); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("got error %v, want connection refused", err)
+	}
+	// The context of every failed attempt is canceled, not only the last one.
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	if len(transport.contexts) != 3 {
+		t.Fatalf("got %d requests, want 3", len(transport.contexts))
+	}
+	for i, ctx := range transport.contexts {
+		if ctx.Err() == nil {
+			t.Errorf("attempt %d: context not canceled", i)
+		}
+	}
+}
+
+func TestClient_WithHedging_fastFailure(t *testing.T) {
+	var requests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if req.URL.Host == "down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "`+req.URL.Host+`"}}}`)
+	})
+	client := graphql.NewClient("http://down/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithHedging(graphql.Hedging{
+			Delay:     time.Second,
+			MaxHedges: 2,
+			Endpoints: graphql.StaticEndpoints{"http://down/graphql", "http://up/graphql"},
+		})
+
+	// The request and the first hedge fail fast, so the hedges are sent without waiting for the delay.
+	var q struct {
+		Viewer struct {
+			Login string
+		}
+	}
+	start := time.Now()
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); q.Viewer.Login != "up" || elapsed >= time.Second {
+		t.Errorf("got response of %s after %v, want the second hedge", q.Viewer.Login, elapsed)
+	}
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("got %d requests, want 3", got)
+	}
+}
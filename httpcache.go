@@ -0,0 +1,291 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WithGETQueries returns a copy of the client that sends queries as GET requests,
+// with the query and the variables in the URL, so that they can be cached by HTTP caches.
+// Mutations are still sent as POST requests.
+func (c *Client) WithGETQueries(get bool) *Client {
+	nc := *c
+	nc.getQueries = get
+	return &nc
+}
+
+// WithHTTPCache returns a copy of the client that caches the responses of GET requests in store,
+// in memory if nil, according to their Cache-Control and ETag headers. See WithGETQueries.
+//
+// Responses are keyed by their URL, and by the request headers named by their Vary header; responses
+// with Vary: * aren't cached. Private responses are only served to requests with the same Authorization
+// header. Responses are fresh for the max-age of their Cache-Control header, minus their Age, and served
+// from the cache without request. Stale responses that have an ETag are kept for a day, and revalidated
+// with an If-None-Match header, then served from the cache if the server replies 304 Not Modified.
+// Responses with no-store aren't cached, and responses with no-cache are always revalidated.
+func (c *Client) WithHTTPCache(store CacheStore) *Client {
+	if store == nil {
+		store = NewMemoryCacheStore(0)
+	}
+	nc := *c
+	nc.httpCache = store
+	return &nc
+}
+
+// isQueryDocument reports whether the document starts with a query operation.
+func isQueryDocument(query string) bool {
+	doc := strings.TrimLeft(query, " \t\r\n,")
+	if strings.HasPrefix(doc, "{") {
+		return true
+	}
+	if !strings.HasPrefix(doc, "query") {
+		return false
+	}
+	rest := doc[len("query"):]
+	return rest == "" || !(rest[0] == '_' || isLetter(rest[0]) || isDigit(rest[0]))
+}
+
+// newGETRequest creates a GET request to rawURL with the query and the encoded variables in its query string.
+func newGETRequest(ctx context.Context, rawURL string, query string, variables map[string]interface{}) (*http.Request, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	params := u.Query()
+	params.Set("query", query)
+	if len(variables) > 0 {
+		b, err := json.Marshal(variables)
+		if err != nil {
+			return nil, err
+		}
+		params.Set("variables", string(b))
+	}
+	u.RawQuery = params.Encode()
+	return http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+}
+
+// httpCacheEntry is a cached response.
+type httpCacheEntry struct {
+	Header  http.Header `json:"header"`
+	Body    []byte      `json:"body"`
+	ETag    string      `json:"etag,omitempty"`
+	Expires time.Time   `json:"expires"`
+	// Vary holds the hashes of the values of the request headers that select the response,
+	// the headers of its Vary header, and Authorization for private responses.
+	Vary map[string]string `json:"vary,omitempty"`
+}
+
+// response returns the cached response to req.
+func (e httpCacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// matches reports whether the entry is the response to req, i.e. req has the same values
+// of the headers that select the response.
+func (e httpCacheEntry) matches(req *http.Request) bool {
+	for name, hash := range e.Vary {
+		if headerHash(req.Header, name) != hash {
+			return false
+		}
+	}
+	return true
+}
+
+// httpCacheRevalidateTTL is how long responses with an ETag are kept after they expire, to be revalidated.
+const httpCacheRevalidateTTL = 24 * time.Hour
+
+// roundTrip sends req, through the HTTP cache for GET requests.
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	if c.httpCache == nil || req.Method != http.MethodGet {
+		return c.sendHTTP(req)
+	}
+	ctx := req.Context()
+	key := "http:" + urlKey(req)
+	var entry httpCacheEntry
+	cached := false
+	if b, ok, err := c.httpCache.Get(ctx, key); err == nil && ok {
+		cached = json.Unmarshal(b, &entry) == nil && entry.matches(req)
+	}
+	if cached && time.Now().Before(entry.Expires) {
+		return entry.response(req), nil
+	}
+	if cached && entry.ETag != "" {
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	resp, err := c.sendHTTP(req)
+	if err != nil {
+		return nil, err
+	}
+	cacheControl := parseCacheControl(resp.Header)
+	if resp.StatusCode == http.StatusNotModified && cached {
+		resp.Body.Close()
+		if !cacheControl.noStore {
+			entry.Expires = cacheControl.expires(resp.Header)
+			c.storeHTTPCacheEntry(ctx, key, entry)
+		}
+		return entry.response(req), nil
+	}
+	etag := resp.Header.Get("ETag")
+	vary, ok := varyHashes(req, resp.Header, cacheControl.private)
+	if resp.StatusCode != http.StatusOK || cacheControl.noStore || !ok || (cacheControl.maxAge <= 0 && etag == "") {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	c.storeHTTPCacheEntry(ctx, key, httpCacheEntry{
+		Header:  resp.Header,
+		Body:    body,
+		ETag:    etag,
+		Expires: cacheControl.expires(resp.Header),
+		Vary:    vary,
+	})
+	return resp, nil
+}
+
+// storeHTTPCacheEntry stores the entry until it expires. Entries with an ETag are kept
+// for httpCacheRevalidateTTL more, to be revalidated.
+func (c *Client) storeHTTPCacheEntry(ctx context.Context, key string, entry httpCacheEntry) {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	ttl := time.Until(entry.Expires)
+	if entry.ETag != "" {
+		ttl += httpCacheRevalidateTTL
+	}
+	if ttl <= 0 {
+		return
+	}
+	_ = c.httpCache.Set(ctx, key, b, ttl)
+}
+
+// urlKey returns a hash of the method and URL of the request.
+func urlKey(req *http.Request) string {
+	h := sha256.Sum256([]byte(req.Method + " " + req.URL.String()))
+	return hex.EncodeToString(h[:])
+}
+
+// varyHashes returns the hashes of the values of the request headers that select the response,
+// the headers named by its Vary header, and Authorization if it's private.
+// It returns false if the response can't be cached, i.e. it varies on every request with Vary: *.
+func varyHashes(req *http.Request, header http.Header, private bool) (map[string]string, bool) {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	if private {
+		names = append(names, "Authorization")
+	}
+	if len(names) == 0 {
+		return nil, true
+	}
+	hashes := make(map[string]string, len(names))
+	for _, name := range names {
+		if name == "*" {
+			return nil, false
+		}
+		name = http.CanonicalHeaderKey(name)
+		hashes[name] = headerHash(req.Header, name)
+	}
+	return hashes, true
+}
+
+// headerHash returns a hash of the values of the header with the name, so that
+// credentials aren't written to the cache store.
+func headerHash(header http.Header, name string) string {
+	h := sha256.Sum256([]byte(strings.Join(header.Values(name), ", ")))
+	return hex.EncodeToString(h[:])
+}
+
+// requestKey returns a hash of the method, URL, headers and body of the request.
+func requestKey(req *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(req.Method + " " + req.URL.String() + "\n"))
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		if name != "If-None-Match" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		h.Write([]byte(name + ": " + strings.Join(req.Header[name], ", ") + "\n"))
+	}
+	h.Write([]byte("\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cacheControl holds the directives of a Cache-Control header that the HTTP cache honors.
+type cacheControl struct {
+	noStore bool
+	private bool
+	maxAge  time.Duration
+}
+
+func parseCacheControl(header http.Header) cacheControl {
+	var cc cacheControl
+	noCache := false
+	for _, directive := range strings.Split(strings.Join(header.Values("Cache-Control"), ","), ",") {
+		name, value := strings.TrimSpace(directive), ""
+		if i := strings.IndexByte(name, '='); i >= 0 {
+			name, value = strings.TrimSpace(name[:i]), strings.Trim(strings.TrimSpace(name[i+1:]), `"`)
+		}
+		switch strings.ToLower(name) {
+		case "no-store":
+			cc.noStore = true
+		case "no-cache":
+			noCache = true
+		case "private":
+			cc.private = true
+		case "max-age":
+			if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+				cc.maxAge = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	if noCache {
+		cc.maxAge = 0
+	}
+	return cc
+}
+
+// expires returns the time at which a response received now expires,
+// its max-age minus the time it spent in caches, from its Age header.
+func (cc cacheControl) expires(header http.Header) time.Time {
+	maxAge := cc.maxAge
+	if age, err := strconv.Atoi(header.Get("Age")); err == nil && age > 0 {
+		maxAge -= time.Duration(age) * time.Second
+	}
+	return time.Now().Add(maxAge)
+}
@@ -0,0 +1,206 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/zainirfan13/graphql-client"
+)
+
+func TestClient_WithHTTPCache(t *testing.T) {
+	var (
+		requests    int
+		ifNoneMatch string
+		header      = http.Header{}
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		if req.Method != http.MethodGet {
+			t.Errorf("got method %s, want GET", req.Method)
+		}
+		if got, want := req.URL.RawQuery, `query=query+%28%24login%3AString%21%29%7Buser%28login%3A+%24login%29%7Bname%7D%7D&variables=%7B%22login%22%3A%22gopher%22%7D`; got != want {
+			t.Errorf("got query string %s, want %s", got, want)
+		}
+		ifNoneMatch = req.Header.Get("If-None-Match")
+		for name, values := range header {
+			w.Header()[name] = values
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if ifNoneMatch != "" && ifNoneMatch == header.Get("ETag") {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		mustWrite(w, `{"data": {"user": {"name": "Gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithGETQueries(true).
+		WithHTTPCache(nil)
+
+	var q struct {
+		User struct {
+			Name string
+		} `graphql:"user(login: $login)"`
+	}
+	query := func() {
+		t.Helper()
+		q.User.Name = ""
+		if err := client.Query(context.Background(), &q, map[string]interface{}{"login": "gopher"}); err != nil {
+			t.Fatal(err)
+		}
+		if q.User.Name != "Gopher" {
+			t.Errorf("got name %q, want Gopher", q.User.Name)
+		}
+	}
+
+	// Fresh responses are served from the cache.
+	header.Set("Cache-Control", "public, max-age=60")
+	query()
+	query()
+	if requests != 1 {
+		t.Errorf("got %d requests, want 1 for a fresh response", requests)
+	}
+
+	// Responses with an ETag are revalidated, and 304 is a hit.
+	client = client.WithHTTPCache(nil)
+	header.Set("Cache-Control", "no-cache")
+	header.Set("ETag", `"v1"`)
+	requests = 0
+	query()
+	query()
+	if requests != 2 || ifNoneMatch != `"v1"` {
+		t.Errorf("got %d requests and If-None-Match %s, want 2 requests with the ETag", requests, ifNoneMatch)
+	}
+
+	// no-store responses aren't cached.
+	client = client.WithHTTPCache(graphql.NewMemoryCacheStore(0))
+	header = http.Header{"Cache-Control": []string{"no-store, max-age=60"}}
+	requests = 0
+	query()
+	query()
+	if requests != 2 || ifNoneMatch != "" {
+		t.Errorf("got %d requests and If-None-Match %s, want 2 requests", requests, ifNoneMatch)
+	}
+
+	// Expired responses are sent again.
+	client = client.WithHTTPCache(nil)
+	header = http.Header{"Cache-Control": []string{"max-age=1"}}
+	requests = 0
+	query()
+	time.Sleep(1100 * time.Millisecond)
+	query()
+	if requests != 2 {
+		t.Errorf("got %d requests, want 2 after expiration", requests)
+	}
+}
+
+func TestClient_WithGETQueries_mutation(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			t.Errorf("got method %s, want POST", req.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"addStar": {"id": "1"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).WithGETQueries(true)
+
+	var m struct {
+		AddStar struct {
+			ID string
+		} `graphql:"addStar(id: \"1\")"`
+	}
+	if err := client.Mutate(context.Background(), &m, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_WithHTTPCache_headers(t *testing.T) {
+	var (
+		requests int
+		header   http.Header
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		for name, values := range header {
+			w.Header()[name] = values
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	var language, authorization string
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithGETQueries(true).
+		WithRequestModifier(func(req *http.Request) {
+			req.Header.Set("X-Amz-Date", time.Now().Format(time.RFC3339Nano))
+			req.Header.Set("Accept-Language", language)
+			req.Header.Set("Authorization", authorization)
+		})
+
+	var q struct {
+		Viewer struct {
+			Login string
+		}
+	}
+	query := func() {
+		t.Helper()
+		if err := client.Query(context.Background(), &q, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name                    string
+		header                  http.Header
+		language, authorization [2]string
+		want                    int
+	}{
+		{
+			name:     "headers out of Vary are ignored",
+			header:   http.Header{"Cache-Control": {"max-age=60"}},
+			language: [2]string{"en", "fr"},
+			want:     1,
+		},
+		{
+			name:     "Vary headers select the response",
+			header:   http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"Accept-Language"}},
+			language: [2]string{"en", "fr"},
+			want:     2,
+		},
+		{
+			name:   "Vary: * isn't cached",
+			header: http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"*"}},
+			want:   2,
+		},
+		{
+			name:          "private responses are served to the same Authorization",
+			header:        http.Header{"Cache-Control": {"private, max-age=60"}},
+			authorization: [2]string{"Bearer a", "Bearer a"},
+			want:          1,
+		},
+		{
+			name:          "private responses aren't served to another Authorization",
+			header:        http.Header{"Cache-Control": {"private, max-age=60"}},
+			authorization: [2]string{"Bearer a", "Bearer b"},
+			want:          2,
+		},
+		{
+			name:   "Age is deducted from max-age",
+			header: http.Header{"Cache-Control": {"max-age=60"}, "Age": {"60"}},
+			want:   2,
+		},
+	}
+	for _, tc := range tests {
+		client = client.WithHTTPCache(nil)
+		header, requests = tc.header, 0
+		for i := 0; i < 2; i++ {
+			language, authorization = tc.language[i], tc.authorization[i]
+			query()
+		}
+		if requests != tc.want {
+			t.Errorf("%s: got %d requests, want %d", tc.name, requests, tc.want)
+		}
+	}
+}
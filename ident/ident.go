@@ -1,10 +1,14 @@
 // Package ident provides functions for parsing and converting identifier names
 // between various naming convention. It has support for MixedCaps, lowerCamelCase,
-// and SCREAMING_SNAKE_CASE naming conventions.
+// snake_case, kebab-case and SCREAMING_SNAKE_CASE naming conventions.
+//
+// The package-level functions use the default initialisms and brands.
+// Use a Namer to customize them.
 package ident
 
 import (
 	"strings"
+	"sync"
 	"unicode"
 	"unicode/utf8"
 )
@@ -13,6 +17,15 @@ import (
 //
 // E.g., "ClientMutationID" -> {"Client", "Mutation", "ID"}.
 func ParseMixedCaps(name string) Name {
+	return defaultNamer.ParseMixedCaps(name)
+}
+
+// ParseMixedCaps parses a MixedCaps identifier name,
+// recognizing the initialisms of the namer.
+//
+// E.g., "ClientMutationID" -> {"Client", "Mutation", "ID"}.
+func (nm *Namer) ParseMixedCaps(name string) Name {
+	nm.init()
 	var words Name
 
 	// Split name at any lower -> Upper or Upper -> Upper,lower transitions.
@@ -26,6 +39,9 @@ func ParseMixedCaps(name string) Name {
 		} else if unicode.IsLower(runes[i]) && unicode.IsUpper(runes[i+1]) {
 			// lower -> Upper.
 			eow = true
+		} else if nm.SplitDigits && unicode.IsDigit(runes[i]) != unicode.IsDigit(runes[i+1]) {
+			// letter -> digit or digit -> letter.
+			eow = true
 		} else if i+2 < len(runes) && unicode.IsUpper(runes[i]) && unicode.IsUpper(runes[i+1]) && unicode.IsLower(runes[i+2]) {
 			// Upper -> Upper,lower. End of acronym, followed by a word.
 			eow = true
@@ -41,15 +57,30 @@ func ParseMixedCaps(name string) Name {
 
 		// [w, i) is a word.
 		word := string(runes[w:i])
-		if initialism, ok := isInitialism(word); ok {
+		if initialism, ok := nm.isInitialism(word); ok {
 			words = append(words, initialism)
-		} else if i1, i2, ok := isTwoInitialisms(word); ok {
+		} else if i1, i2, ok := nm.isTwoInitialisms(word); ok {
 			words = append(words, i1, i2)
 		} else {
 			words = append(words, word)
 		}
 		w = i
 	}
+	if nm.SplitDigits {
+		words = nm.joinDigitInitialisms(words)
+	}
+	return words
+}
+
+// joinDigitInitialisms joins back the words of initialisms
+// that contain digits, such as "UTF8", after splitting at digits.
+func (nm *Namer) joinDigitInitialisms(words Name) Name {
+	for i := 0; i+1 < len(words); i++ {
+		if initialism, ok := nm.isInitialism(words[i] + words[i+1]); ok {
+			words[i] = initialism
+			words = append(words[:i+1], words[i+2:]...)
+		}
+	}
 	return words
 }
 
@@ -57,6 +88,14 @@ func ParseMixedCaps(name string) Name {
 //
 // E.g., "clientMutationId" -> {"client", "Mutation", "Id"}.
 func ParseLowerCamelCase(name string) Name {
+	return defaultNamer.ParseLowerCamelCase(name)
+}
+
+// ParseLowerCamelCase parses a lowerCamelCase identifier name.
+// If the namer splits digits, digits are separate words.
+//
+// E.g., "clientMutationId" -> {"client", "Mutation", "Id"}.
+func (nm *Namer) ParseLowerCamelCase(name string) Name {
 	var words Name
 
 	// Split name at any Upper letters.
@@ -69,6 +108,9 @@ func ParseLowerCamelCase(name string) Name {
 		} else if unicode.IsUpper(runes[i+1]) {
 			// Upper letter.
 			eow = true
+		} else if nm.SplitDigits && unicode.IsDigit(runes[i]) != unicode.IsDigit(runes[i+1]) {
+			// letter -> digit or digit -> letter.
+			eow = true
 		}
 		i++
 		if !eow {
@@ -86,31 +128,28 @@ func ParseLowerCamelCase(name string) Name {
 //
 // E.g., "CLIENT_MUTATION_ID" -> {"CLIENT", "MUTATION", "ID"}.
 func ParseScreamingSnakeCase(name string) Name {
-	var words Name
+	return parseDelimited(name, '_')
+}
 
-	// Split name at '_' characters.
-	runes := []rune(name)
-	w, i := 0, 0 // Index of start of word, scan.
-	for i+1 <= len(runes) {
-		eow := false // Whether we hit the end of a word.
-		if i+1 == len(runes) {
-			eow = true
-		} else if runes[i+1] == '_' {
-			// Underscore.
-			eow = true
-		}
-		i++
-		if !eow {
-			continue
-		}
+// ParseSnakeCase parses a snake_case identifier name.
+//
+// E.g., "client_mutation_id" -> {"client", "mutation", "id"}.
+func ParseSnakeCase(name string) Name {
+	return parseDelimited(name, '_')
+}
 
-		// [w, i) is a word.
-		words = append(words, string(runes[w:i]))
-		if i < len(runes) && runes[i] == '_' {
-			// Skip underscore.
-			i++
-		}
-		w = i
+// ParseKebabCase parses a kebab-case identifier name.
+//
+// E.g., "client-mutation-id" -> {"client", "mutation", "id"}.
+func ParseKebabCase(name string) Name {
+	return parseDelimited(name, '-')
+}
+
+// parseDelimited splits name at sep characters, skipping empty words.
+func parseDelimited(name string, sep rune) Name {
+	var words Name
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool { return r == sep }) {
+		words = append(words, word)
 	}
 	return words
 }
@@ -122,16 +161,25 @@ type Name []string
 //
 // E.g., "ClientMutationID".
 func (n Name) ToMixedCaps() string {
+	return defaultNamer.ToMixedCaps(n)
+}
+
+// ToMixedCaps expresses identifer name in MixedCaps naming convention,
+// spelling the initialisms and brands of the namer.
+//
+// E.g., "ClientMutationID".
+func (nm *Namer) ToMixedCaps(n Name) string {
+	nm.init()
 	for i, word := range n {
 		if strings.EqualFold(word, "IDs") { // Special case, plural form of ID initialism.
 			n[i] = "IDs"
 			continue
 		}
-		if initialism, ok := isInitialism(word); ok {
+		if initialism, ok := nm.isInitialism(word); ok {
 			n[i] = initialism
 			continue
 		}
-		if brand, ok := isBrand(word); ok {
+		if brand, ok := nm.isBrand(word); ok {
 			n[i] = brand
 			continue
 		}
@@ -156,19 +204,122 @@ func (n Name) ToLowerCamelCase() string {
 	return strings.Join(n, "")
 }
 
+// ToSnakeCase expresses identifer name in snake_case naming convention.
+//
+// E.g., "client_mutation_id".
+func (n Name) ToSnakeCase() string {
+	return n.join("_", strings.ToLower)
+}
+
+// ToScreamingSnakeCase expresses identifer name in SCREAMING_SNAKE_CASE naming convention.
+//
+// E.g., "CLIENT_MUTATION_ID".
+func (n Name) ToScreamingSnakeCase() string {
+	return n.join("_", strings.ToUpper)
+}
+
+// ToKebabCase expresses identifer name in kebab-case naming convention.
+//
+// E.g., "client-mutation-id".
+func (n Name) ToKebabCase() string {
+	return n.join("-", strings.ToLower)
+}
+
+// join converts each word with fn and joins them with sep.
+// Unlike ToMixedCaps, it doesn't modify n.
+func (n Name) join(sep string, fn func(string) string) string {
+	words := make([]string, len(n))
+	for i, word := range n {
+		words[i] = fn(word)
+	}
+	return strings.Join(words, sep)
+}
+
+// Namer parses and expresses identifier names with its own registry
+// of initialisms and brands, so that they can be customized without
+// changing the behavior of the package-level functions.
+//
+// The zero value is ready to use, with the default initialisms and brands.
+type Namer struct {
+	once        sync.Once
+	initialisms map[string]struct{}
+	brands      map[string]string
+
+	// SplitDigits makes letter -> digit and digit -> letter transitions
+	// word boundaries when parsing, except inside initialisms such as "UTF8".
+	//
+	// E.g., "AddressLine1" -> {"Address", "Line", "1"}.
+	SplitDigits bool
+}
+
+// defaultNamer is used by the package-level functions.
+var defaultNamer = &Namer{initialisms: initialisms, brands: brands}
+
+// NewNamer creates a namer with a copy of the default initialisms and brands.
+func NewNamer() *Namer {
+	nm := &Namer{}
+	nm.init()
+	return nm
+}
+
+// init copies the default initialisms and brands into a zero namer.
+func (nm *Namer) init() {
+	nm.once.Do(func() {
+		if nm.initialisms == nil {
+			nm.initialisms = make(map[string]struct{}, len(initialisms))
+			for k := range initialisms {
+				nm.initialisms[k] = struct{}{}
+			}
+		}
+		if nm.brands == nil {
+			nm.brands = make(map[string]string, len(brands))
+			for k, v := range brands {
+				nm.brands[k] = v
+			}
+		}
+	})
+}
+
+// AddInitialisms registers additional initialisms, e.g. "GQL".
+func (nm *Namer) AddInitialisms(initialisms ...string) *Namer {
+	nm.init()
+	for _, initialism := range initialisms {
+		nm.initialisms[strings.ToUpper(initialism)] = struct{}{}
+	}
+	return nm
+}
+
+// RemoveInitialisms unregisters initialisms, so that they are spelled as regular words.
+func (nm *Namer) RemoveInitialisms(initialisms ...string) *Namer {
+	nm.init()
+	for _, initialism := range initialisms {
+		delete(nm.initialisms, strings.ToUpper(initialism))
+	}
+	return nm
+}
+
+// AddBrands registers additional brands by their canonical spelling, e.g. "GraphQL".
+func (nm *Namer) AddBrands(brands ...string) *Namer {
+	nm.init()
+	for _, brand := range brands {
+		nm.brands[strings.ToLower(brand)] = brand
+	}
+	return nm
+}
+
 // isInitialism reports whether word is an initialism.
-func isInitialism(word string) (string, bool) {
+func (nm *Namer) isInitialism(word string) (string, bool) {
 	initialism := strings.ToUpper(word)
-	_, ok := initialisms[initialism]
+	_, ok := nm.initialisms[initialism]
 	return initialism, ok
 }
 
 // isTwoInitialisms reports whether word is two initialisms.
-func isTwoInitialisms(word string) (string, string, bool) {
+func (nm *Namer) isTwoInitialisms(word string) (string, string, bool) {
 	word = strings.ToUpper(word)
 	for i := 2; i <= len(word)-2; i++ { // Shortest initialism is 2 characters long.
-		_, ok1 := initialisms[word[:i]]
-		_, ok2 := initialisms[word[i:]]
+		_, ok1 := nm.initialisms[word[:i]]
+		_, ok2 := nm.initialisms[word[i:]]
 		if ok1 && ok2 {
 			return word[:i], word[i:], true
 		}
@@ -226,8 +377,8 @@ var initialisms = map[string]struct{}{
 }
 
 // isBrand reports whether word is a brand.
-func isBrand(word string) (string, bool) {
-	brand, ok := brands[strings.ToLower(word)]
+func (nm *Namer) isBrand(word string) (string, bool) {
+	brand, ok := nm.brands[strings.ToLower(word)]
 	return brand, ok
 }
 
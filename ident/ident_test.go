@@ -26,6 +26,24 @@ func Example_mixedCapsToLowerCamelCase() {
 	// Output: clientMutationId
 }
 
+func Example_mixedCapsToSnakeCase() {
+	fmt.Println(ident.ParseMixedCaps("ClientMutationID").ToSnakeCase())
+
+	// Output: client_mutation_id
+}
+
+func Example_namer() {
+	namer := ident.NewNamer().AddInitialisms("GQL").AddBrands("GraphQL")
+	namer.SplitDigits = true
+
+	fmt.Println(namer.ParseMixedCaps("GQLAddressLine1").ToSnakeCase())
+	fmt.Println(namer.ToMixedCaps(ident.ParseSnakeCase("graphql_gql_schema")))
+
+	// Output:
+	// gql_address_line_1
+	// GraphQLGQLSchema
+}
+
 func TestParseMixedCaps(t *testing.T) {
 	tests := []struct {
 		in   string
@@ -42,6 +60,11 @@ func TestParseMixedCaps(t *testing.T) {
 		{in: "HTTPSSQL", want: ident.Name{"HTTPS", "SQL"}},
 		{in: "UserIDs", want: ident.Name{"User", "IDs"}},
 		{in: "TeamIDsSorted", want: ident.Name{"Team", "IDs", "Sorted"}},
+		{in: "UTF8String", want: ident.Name{"UTF8String"}},
+		{in: "Sha256Hash", want: ident.Name{"Sha256Hash"}},
+		{in: "AddressLine1", want: ident.Name{"Address", "Line1"}},
+		{in: "Address1Line", want: ident.Name{"Address1Line"}},
+		{in: "V2API", want: ident.Name{"V2API"}},
 	}
 	for _, tc := range tests {
 		got := ident.ParseMixedCaps(tc.in)
@@ -51,6 +74,52 @@ func TestParseMixedCaps(t *testing.T) {
 	}
 }
 
+func TestNamer_ParseMixedCaps_splitDigits(t *testing.T) {
+	namer := ident.NewNamer()
+	namer.SplitDigits = true
+	tests := []struct {
+		in   string
+		want ident.Name
+	}{
+		{in: "AddressLine1", want: ident.Name{"Address", "Line", "1"}},
+		{in: "Sha256Hash", want: ident.Name{"Sha", "256", "Hash"}},
+		{in: "UTF8String", want: ident.Name{"UTF8", "String"}},
+		{in: "Top10Users", want: ident.Name{"Top", "10", "Users"}},
+		{in: "Address1Line", want: ident.Name{"Address", "1", "Line"}},
+	}
+	for _, tc := range tests {
+		got := namer.ParseMixedCaps(tc.in)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("got: %q, want: %q", got, tc.want)
+		}
+	}
+}
+
+func TestNamer_initialisms(t *testing.T) {
+	namer := ident.NewNamer().AddInitialisms("GQL").RemoveInitialisms("ID")
+	if got, want := namer.ParseMixedCaps("GQLSchemaID").ToSnakeCase(), "gql_schema_id"; got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+	if got, want := namer.ToMixedCaps(ident.Name{"gql", "schema", "id"}), "GQLSchemaId"; got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+	// The package-level functions aren't affected.
+	if got, want := ident.ParseSnakeCase("gql_schema_id").ToMixedCaps(), "GqlSchemaID"; got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+}
+
+func TestNamer_zeroValue(t *testing.T) {
+	var namer ident.Namer
+	namer.AddInitialisms("GQL").AddBrands("GraphQL")
+	if got, want := namer.ParseMixedCaps("GQLSchemaID").ToSnakeCase(), "gql_schema_id"; got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+	if got, want := namer.ToMixedCaps(ident.Name{"graphql", "github", "id"}), "GraphQLGitHubID"; got != want {
+		t.Errorf("got: %q, want: %q", got, want)
+	}
+}
+
 func TestParseLowerCamelCase(t *testing.T) {
 	tests := []struct {
 		in   string
@@ -81,6 +150,83 @@ func TestParseScreamingSnakeCase(t *testing.T) {
 	}
 }
 
+func TestParseSnakeCase(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ident.Name
+	}{
+		{in: "client_mutation_id", want: ident.Name{"client", "mutation", "id"}},
+		{in: "_private__name_", want: ident.Name{"private", "name"}},
+	}
+	for _, tc := range tests {
+		got := ident.ParseSnakeCase(tc.in)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("got: %q, want: %q", got, tc.want)
+		}
+	}
+}
+
+func TestParseKebabCase(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ident.Name
+	}{
+		{in: "client-mutation-id", want: ident.Name{"client", "mutation", "id"}},
+	}
+	for _, tc := range tests {
+		got := ident.ParseKebabCase(tc.in)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("got: %q, want: %q", got, tc.want)
+		}
+	}
+}
+
+func TestName_ToSnakeCase(t *testing.T) {
+	tests := []struct {
+		in   ident.Name
+		want string
+	}{
+		{in: ident.Name{"Client", "Mutation", "ID"}, want: "client_mutation_id"},
+		{in: ident.Name{"CLIENT", "MUTATION", "ID"}, want: "client_mutation_id"},
+	}
+	for _, tc := range tests {
+		got := tc.in.ToSnakeCase()
+		if got != tc.want {
+			t.Errorf("got: %q, want: %q", got, tc.want)
+		}
+	}
+}
+
+func TestName_ToKebabCase(t *testing.T) {
+	tests := []struct {
+		in   ident.Name
+		want string
+	}{
+		{in: ident.Name{"Client", "Mutation", "ID"}, want: "client-mutation-id"},
+	}
+	for _, tc := range tests {
+		got := tc.in.ToKebabCase()
+		if got != tc.want {
+			t.Errorf("got: %q, want: %q", got, tc.want)
+		}
+	}
+}
+
+func TestName_ToScreamingSnakeCase(t *testing.T) {
+	tests := []struct {
+		in   ident.Name
+		want string
+	}{
+		{in: ident.Name{"client", "Mutation", "Id"}, want: "CLIENT_MUTATION_ID"},
+	}
+	for _, tc := range tests {
+		got := tc.in.ToScreamingSnakeCase()
+		if got != tc.want {
+			t.Errorf("got: %q, want: %q", got, tc.want)
+		}
+	}
+}
+
 func TestName_ToMixedCaps(t *testing.T) {
 	tests := []struct {
 		in   ident.Name
@@ -130,6 +276,9 @@ func TestMixedCapsToLowerCamelCase(t *testing.T) {
 		{in: "IDsAndNames", want: "idsAndNames"},
 		{in: "UserIDs", want: "userIds"},
 		{in: "TeamIDsSorted", want: "teamIdsSorted"},
+		{in: "Address1Line", want: "address1line"},
+		{in: "Base64URL", want: "base64url"},
+		{in: "V2API", want: "v2api"},
 	}
 	for _, tc := range tests {
 		got := ident.ParseMixedCaps(tc.in).ToLowerCamelCase()
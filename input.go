@@ -0,0 +1,436 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// requestVariables returns the JSON encoded variables to send in the request.
+// Vars without value are left out, so that the server uses their default values,
+// and so are omitted Omittable values.
+//
+// Input-object structs are encoded with encoding/json, except that fields
+// without json tag are named by their graphql tag, if any. Values that
+// encoding/json encodes as is aren't encoded in advance, and variables is
+// returned as is if none of its values needs to be.
+func requestVariables(variables map[string]interface{}) (map[string]interface{}, error) {
+	if !needsRequestEncoding(variables) {
+		return variables, nil
+	}
+	out := make(map[string]interface{}, len(variables))
+	for name, value := range variables {
+		if isOmittedVariable(value) {
+			continue
+		}
+		if value == nil || !needsInputEncoding(reflect.TypeOf(value)) {
+			out[name] = value
+			continue
+		}
+		b, err := marshalInput(reflect.ValueOf(value))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode variable $%s: %w", name, err)
+		}
+		out[name] = json.RawMessage(b)
+	}
+	return out, nil
+}
+
+// needsRequestEncoding reports whether any variable is omitted or needs to be encoded by marshalInput.
+func needsRequestEncoding(variables map[string]interface{}) bool {
+	for _, value := range variables {
+		if value != nil && (isOmittedVariable(value) || needsInputEncoding(reflect.TypeOf(value))) {
+			return true
+		}
+	}
+	return false
+}
+
+// isOmittedVariable reports whether the variable value is left out of the request.
+func isOmittedVariable(value interface{}) bool {
+	if v, ok := asVar(value); ok {
+		if v.Value == nil {
+			return true
+		}
+		value = v.Value
+	}
+	o, ok := asOmittable(value)
+	return ok && o.IsOmitted()
+}
+
+// marshalInput returns the JSON encoding of the input value v.
+// Values whose types don't need the graphql tags are encoded with json.Marshal as a whole.
+func marshalInput(v reflect.Value) ([]byte, error) {
+	if !v.IsValid() {
+		return []byte("null"), nil
+	}
+	t := v.Type()
+	if t == omittableType {
+		return v.Interface().(Omittable).MarshalJSON()
+	}
+	if !needsInputEncoding(t) {
+		return json.Marshal(v.Interface())
+	}
+
+	var buf bytes.Buffer
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return []byte("null"), nil
+		}
+		return marshalInput(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && v.IsNil() {
+			return []byte("null"), nil
+		}
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			b, err := marshalInput(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(b)
+		}
+		buf.WriteByte(']')
+	case reflect.Map:
+		if v.IsNil() {
+			return []byte("null"), nil
+		}
+		if t.Key().Kind() != reflect.String {
+			return json.Marshal(v.Interface())
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		buf.WriteByte('{')
+		more := false
+		for _, key := range keys {
+			value := v.MapIndex(key)
+			if isOmitted(value) {
+				continue
+			}
+			if more {
+				buf.WriteByte(',')
+			}
+			if err := writeInputField(&buf, key.String(), value); err != nil {
+				return nil, err
+			}
+			more = true
+		}
+		buf.WriteByte('}')
+	case reflect.Struct:
+		buf.WriteByte('{')
+		if _, err := writeInputStructFields(&buf, v, false); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('}')
+	default:
+		return json.Marshal(v.Interface())
+	}
+	return buf.Bytes(), nil
+}
+
+// writeInputStructFields writes the fields of struct v to buf, inlining the fields of embedded structs.
+// more indicates whether fields were already written, and it's returned updated.
+func writeInputStructFields(buf *bytes.Buffer, v reflect.Value, more bool) (bool, error) {
+	for _, f := range inputFields(v.Type()) {
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok {
+			// Field of a nil embedded pointer.
+			continue
+		}
+		if (f.omitEmpty && isEmptyValue(fv)) || isOmitted(fv) {
+			continue
+		}
+		if more {
+			buf.WriteByte(',')
+		}
+		if err := writeInputField(buf, f.name, fv); err != nil {
+			return more, err
+		}
+		more = true
+	}
+	return more, nil
+}
+
+// inputField is a field of an input-object struct, possibly promoted from an embedded struct.
+type inputField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	tagged    bool // Whether the name is set by a json or graphql tag.
+}
+
+// inputFields returns the fields of struct type t, in the order of their index sequences.
+// As with encoding/json, fields of embedded structs are promoted according to the
+// dominance rules of Go, tagged fields taking precedence over untagged ones at the
+// same depth, and conflicting fields are left out.
+func inputFields(t reflect.Type) []inputField {
+	if cached, ok := inputFieldsCache.Load(t); ok {
+		return cached.([]inputField)
+	}
+	fields := computeInputFields(t)
+	inputFieldsCache.Store(t, fields)
+	return fields
+}
+
+var inputFieldsCache sync.Map // map[reflect.Type][]inputField
+
+func computeInputFields(t reflect.Type) []inputField {
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+	var fields []inputField
+	var current []embedded
+	next := []embedded{{typ: t}}
+	var count, nextCount map[reflect.Type]int
+	visited := map[reflect.Type]bool{}
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[reflect.Type]int{}
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+			visited[e.typ] = true
+			for i := 0; i < e.typ.NumField(); i++ {
+				sf := e.typ.Field(i)
+				name, omitEmpty, ok := inputFieldName(sf)
+				if !ok {
+					continue
+				}
+				index := make([]int, len(e.index)+1)
+				copy(index, e.index)
+				index[len(e.index)] = i
+				if name != "" {
+					fields = append(fields, inputField{
+						name:      name,
+						index:     index,
+						omitEmpty: omitEmpty,
+						tagged:    hasJSONName(sf) || isInputName(sf.Tag.Get("graphql")),
+					})
+					if count[e.typ] > 1 {
+						// The embedded struct appears several times at this depth,
+						// so its fields conflict with themselves.
+						fields = append(fields, fields[len(fields)-1])
+					}
+					continue
+				}
+				ft := sf.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				nextCount[ft]++
+				if nextCount[ft] == 1 {
+					next = append(next, embedded{typ: ft, index: index})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		if fields[i].name != fields[j].name {
+			return fields[i].name < fields[j].name
+		}
+		if len(fields[i].index) != len(fields[j].index) {
+			return len(fields[i].index) < len(fields[j].index)
+		}
+		return fields[i].tagged && !fields[j].tagged
+	})
+	dominant := fields[:0]
+	for i, n := 0, 0; i < len(fields); i += n {
+		for n = 1; i+n < len(fields) && fields[i+n].name == fields[i].name; n++ {
+		}
+		// The first field dominates, unless the next one has the same depth and tagging.
+		if n > 1 && len(fields[i].index) == len(fields[i+1].index) && fields[i].tagged == fields[i+1].tagged {
+			continue
+		}
+		dominant = append(dominant, fields[i])
+	}
+	sort.Slice(dominant, func(i, j int) bool {
+		a, b := dominant[i].index, dominant[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return dominant
+}
+
+// fieldByIndex returns the field of struct v at the index sequence,
+// and false if it's in a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 {
+			for v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					return reflect.Value{}, false
+				}
+				v = v.Elem()
+			}
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// writeInputField writes the name and the JSON encoding of v to buf.
+func writeInputField(buf *bytes.Buffer, name string, v reflect.Value) error {
+	key, err := json.Marshal(name)
+	if err != nil {
+		return err
+	}
+	b, err := marshalInput(v)
+	if err != nil {
+		return fmt.Errorf("field %s: %w", name, err)
+	}
+	buf.Write(key)
+	buf.WriteByte(':')
+	buf.Write(b)
+	return nil
+}
+
+// inputFieldName returns the JSON name of struct field f and whether it has the omitempty option.
+// The name is taken from the json tag, then the graphql tag, then the field name.
+// It returns an empty name for embedded structs without name, and false for skipped fields.
+func inputFieldName(f reflect.StructField) (name string, omitEmpty bool, ok bool) {
+	if f.PkgPath != "" && !f.Anonymous {
+		// Skip unexported field.
+		return "", false, false
+	}
+	jsonTag, hasJSONTag := f.Tag.Lookup("json")
+	if jsonTag == "-" {
+		return "", false, false
+	}
+	parts := strings.Split(jsonTag, ",")
+	name = parts[0]
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	if name != "" {
+		return name, omitEmpty, true
+	}
+	graphqlTag := f.Tag.Get("graphql")
+	if graphqlTag == "-" && !hasJSONTag {
+		return "", false, false
+	}
+	if isInputName(graphqlTag) {
+		return graphqlTag, omitEmpty, true
+	}
+	if f.Anonymous {
+		t := f.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct {
+			return "", false, true
+		}
+		if f.PkgPath != "" {
+			return "", false, false
+		}
+	}
+	return f.Name, omitEmpty, true
+}
+
+// isInputName reports whether the graphql tag s is a plain name,
+// rather than a field selection with arguments, alias or directives.
+func isInputName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || (i > 0 && '0' <= r && r <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// needsInputEncoding reports whether values of type t may contain structs
+// whose fields are named by graphql tags or Omittable values,
+// so that they can't be encoded with json.Marshal.
+// Interfaces may hold any value, so they always need it.
+func needsInputEncoding(t reflect.Type) bool {
+	if cached, ok := inputEncodingCache.Load(t); ok {
+		return cached.(bool)
+	}
+	needs := computeNeedsInputEncoding(t, map[reflect.Type]bool{})
+	inputEncodingCache.Store(t, needs)
+	return needs
+}
+
+var inputEncodingCache sync.Map // map[reflect.Type]bool
+
+func computeNeedsInputEncoding(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if t == omittableType {
+		return true
+	}
+	if t.Implements(jsonMarshaler) {
+		return false
+	}
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return computeNeedsInputEncoding(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" && !f.Anonymous {
+				continue
+			}
+			if name, _, ok := inputFieldName(f); ok && name != "" && name != f.Name && !hasJSONName(f) {
+				return true
+			}
+			if _, ok := f.Tag.Lookup("json"); !ok && f.Tag.Get("graphql") == "-" {
+				return true
+			}
+			if computeNeedsInputEncoding(f.Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasJSONName reports whether the json tag of f sets its name.
+func hasJSONName(f reflect.StructField) bool {
+	return strings.Split(f.Tag.Get("json"), ",")[0] != ""
+}
+
+// isEmptyValue reports whether v is empty according to the omitempty option of encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
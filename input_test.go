@@ -0,0 +1,234 @@
+package graphql
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type reviewInput struct {
+	Stars      int     `graphql:"stars"`
+	Body       *string `graphql:"body" json:",omitempty"`
+	Author     string  `json:"author_login"`
+	Secret     string  `graphql:"-"`
+	Tags       []tagInput
+	CreatedAt  time.Time `graphql:"createdAt"`
+	Unexported bool
+	unexported bool
+}
+
+type tagInput struct {
+	Name string `graphql:"name"`
+}
+
+// jsonInput doesn't need the graphql tags, so it's encoded by encoding/json as a whole.
+type jsonInput struct {
+	Name  string `json:"name"`
+	Count int    `json:",omitempty"`
+}
+
+func TestRequestVariables(t *testing.T) {
+	body := "Great"
+	tests := []struct {
+		in   map[string]interface{}
+		want string
+	}{
+		{
+			in: map[string]interface{}{
+				"review": reviewInput{
+					Stars:     5,
+					Body:      &body,
+					Author:    "gopher",
+					Secret:    "hidden",
+					Tags:      []tagInput{{Name: "go"}},
+					CreatedAt: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+				},
+			},
+			want: `{"review":{"stars":5,"body":"Great","author_login":"gopher","Tags":[{"name":"go"}],"createdAt":"2022-01-02T03:04:05Z","Unexported":false}}`,
+		},
+		{
+			in: map[string]interface{}{
+				"review": &reviewInput{},
+			},
+			want: `{"review":{"stars":0,"author_login":"","Tags":null,"createdAt":"0001-01-01T00:00:00Z","Unexported":false}}`,
+		},
+		{
+			in: map[string]interface{}{
+				"reviews": []interface{}{&reviewInput{Tags: []tagInput{}}, nil},
+				"filter":  map[string]interface{}{"tag": tagInput{Name: "go"}, "limit": 10},
+				"plain":   jsonInput{Name: "x"},
+				"first":   Var{Value: []tagInput{{Name: "a"}}, Type: "[TagInput!]"},
+				"after":   Var{Type: "String", Default: "x"},
+			},
+			want: `{"filter":{"limit":10,"tag":{"name":"go"}},"first":[{"name":"a"}],"plain":{"name":"x"},"reviews":[{"stars":0,"author_login":"","Tags":[],"createdAt":"0001-01-01T00:00:00Z","Unexported":false},null]}`,
+		},
+	}
+	for i, tc := range tests {
+		variables, err := requestVariables(tc.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := json.Marshal(variables)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("test case %d:\n got: %s\nwant: %s", i, got, tc.want)
+		}
+	}
+}
+
+func TestRequestVariables_plain(t *testing.T) {
+	// Plain values are sent as is, without copying the map.
+	in := map[string]interface{}{"login": "gopher", "input": jsonInput{Name: "x"}}
+	got, err := requestVariables(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.ValueOf(got).Pointer() != reflect.ValueOf(in).Pointer() {
+		t.Error("got a copy of the variables")
+	}
+
+	in["tag"] = tagInput{Name: "go"}
+	if got, err = requestVariables(in); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["tag"].(json.RawMessage); !ok {
+		t.Errorf("got tag %#v, want it encoded", got["tag"])
+	}
+	if _, ok := got["input"].(jsonInput); !ok {
+		t.Errorf("got input %#v, want it as is", got["input"])
+	}
+}
+
+type embeddedBaseInput struct {
+	ID   string `graphql:"id"`
+	Name string `graphql:"name"`
+}
+
+type embeddedOtherInput struct {
+	ID   string `graphql:"id"`
+	Note string `graphql:"note"`
+}
+
+func TestMarshalInput_embedded(t *testing.T) {
+	type input struct {
+		embeddedBaseInput
+		*embeddedOtherInput
+		Name string `graphql:"name"`
+	}
+	tests := []struct {
+		in   input
+		want string
+	}{
+		{
+			// The conflicting ids are left out, and the shallower name dominates.
+			in:   input{embeddedBaseInput{ID: "1", Name: "base"}, &embeddedOtherInput{ID: "2", Note: "n"}, "top"},
+			want: `{"note":"n","name":"top"}`,
+		},
+		{
+			in:   input{embeddedBaseInput{ID: "1", Name: "base"}, nil, "top"},
+			want: `{"name":"top"}`,
+		},
+	}
+	for _, tc := range tests {
+		got, err := marshalInput(reflect.ValueOf(tc.in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("got: %s, want: %s", got, tc.want)
+		}
+	}
+
+	// Tagged fields dominate untagged ones at the same depth.
+	type untagged struct {
+		Name string
+	}
+	type tagged struct {
+		Title string `graphql:"Name"`
+	}
+	got, err := marshalInput(reflect.ValueOf(struct {
+		untagged
+		tagged
+	}{untagged{"untagged"}, tagged{"tagged"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"Name":"tagged"}`; string(got) != want {
+		t.Errorf("got: %s, want: %s", got, want)
+	}
+}
+
+func TestNeedsInputEncoding(t *testing.T) {
+	type recursive struct {
+		Name     string `json:"name"`
+		Children []recursive
+	}
+	tests := []struct {
+		in   interface{}
+		want bool
+	}{
+		{in: 0, want: false},
+		{in: jsonInput{}, want: false},
+		{in: recursive{}, want: false},
+		{in: time.Time{}, want: false},
+		{in: tagInput{}, want: true},
+		{in: map[string][]*tagInput{}, want: true},
+		{in: []interface{}{}, want: true},
+		{in: struct{ tagInput }{}, want: true},
+	}
+	for _, tc := range tests {
+		if got := needsInputEncoding(reflect.TypeOf(tc.in)); got != tc.want {
+			t.Errorf("%T: got %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestVariablesFrom(t *testing.T) {
+	type variables struct {
+		Login   string `graphql:"login"`
+		First   int    `type:"Int"`
+		After   Var    `type:"Cursor"`
+		Review  reviewInput
+		Ignored string `graphql:"-"`
+		private string
+	}
+	got, err := VariablesFrom(&variables{Login: "gopher", First: 10, private: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"login":  "gopher",
+		"first":  Var{Value: 10, Type: "Int"},
+		"after":  Var{Type: "Cursor"},
+		"review": reviewInput{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %#v\nwant: %#v", got, want)
+	}
+
+	var q struct {
+		Viewer struct {
+			Login string
+		} `graphql:"user(login: $login)"`
+	}
+	query, err := ConstructQuery(&q, got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `query ($after:Cursor$first:Int$login:String!$review:reviewInput!){user(login: $login){login}}`; query != want {
+		t.Errorf("got: %q, want: %q", query, want)
+	}
+
+	if _, err := VariablesFrom(map[string]interface{}{}); err == nil {
+		t.Error("got no error for map")
+	}
+	if _, err := VariablesFrom(struct {
+		A string `graphql:"a"`
+		B string `graphql:"a"`
+	}{}); err == nil || err.Error() != "duplicate variable $a" {
+		t.Errorf("got error: %v, want duplicate variable", err)
+	}
+}
@@ -19,9 +19,29 @@ import (
 // The implementation is created on top of the JSON tokenizer available
 // in "encoding/json".Decoder.
 func UnmarshalGraphQL(data []byte, v interface{}) error {
+	return UnmarshalGraphQLWithOptions(data, v, Options{})
+}
+
+// Options customizes how UnmarshalGraphQLWithOptions decodes the response.
+type Options struct {
+	// FieldName converts the name of a struct field without graphql tag
+	// into its GraphQL name. If nil, the field name is matched case-insensitively.
+	FieldName func(fieldName string) string
+
+	// IsScalar reports whether values of type t are custom scalars, which are
+	// decoded as a whole with encoding/json even if t is a struct.
+	// It's consulted in addition to the scalar tag and json.Unmarshaler.
+	IsScalar func(t reflect.Type) bool
+}
+
+// UnmarshalGraphQLWithOptions is like UnmarshalGraphQL, but decodes with the provided options.
+func UnmarshalGraphQLWithOptions(data []byte, v interface{}, opts Options) error {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && !rv.IsNil() && hasMapSelection(rv.Elem().Type()) {
+		return unmarshalRaw(data, rv.Elem(), opts)
+	}
 	dec := json.NewDecoder(bytes.NewReader(data))
 	dec.UseNumber()
-	err := (&decoder{tokenizer: dec}).Decode(v)
+	err := (&decoder{tokenizer: dec, opts: opts}).Decode(v)
 	if err != nil {
 		return err
 	}
@@ -46,6 +66,8 @@ type decoder struct {
 		Decode(v interface{}) error
 	}
 
+	opts Options
+
 	// Stack of what part of input JSON we're in the middle of - objects, arrays.
 	parseState []json.Delim
 
@@ -74,7 +96,12 @@ func (d *decoder) Decode(v interface{}) error {
 	if rv.Kind() != reflect.Ptr {
 		return fmt.Errorf("cannot decode into non-pointer %T", v)
 	}
-	d.vs = []stack{{rv.Elem()}}
+	if isSelection(rv) {
+		// Keep the pointer, which gives access to the ordered map of the selection.
+		d.vs = []stack{{rv}}
+	} else {
+		d.vs = []stack{{rv.Elem()}}
+	}
 	return d.decode()
 }
 
@@ -106,26 +133,31 @@ func (d *decoder) decode() error {
 			// If one field is raw all must be treated as raw
 			rawMessage := false
 			isScalar := false
+			mapSelection := false
 			for i := range d.vs {
-				v := d.vs[i].Top()
-				for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
-					v = v.Elem()
-				}
+				v := indirect(d.vs[i].Top())
 				var f reflect.Value
 				switch v.Kind() {
 				case reflect.Struct:
-					f, isScalar = fieldByGraphQLName(v, key)
+					f, isScalar = fieldByGraphQLName(v, key, d.opts)
 					if f.IsValid() {
 						someFieldExist = true
 						// Check for special embedded json
 						if f.Type() == rawMessageValue.Type() {
 							rawMessage = true
 						}
+						if hasMapSelection(f.Type()) {
+							mapSelection = true
+						}
 					}
 				case reflect.Slice:
 					f = orderedMapValueByGraphQLName(v, key)
 					if f.IsValid() {
 						someFieldExist = true
+						if isUntyped(f) {
+							// Decode untyped values, which may be objects or arrays, as a whole.
+							isScalar = true
+						}
 					}
 				}
 				d.vs[i] = append(d.vs[i], f)
@@ -134,7 +166,7 @@ func (d *decoder) decode() error {
 				return fmt.Errorf("struct field for %q doesn't exist in any of %v places to unmarshal", key, len(d.vs))
 			}
 
-			if rawMessage || isScalar {
+			if rawMessage || isScalar || mapSelection {
 				// Read the next complete object from the json stream
 				var data json.RawMessage
 				err = d.tokenizer.Decode(&data)
@@ -189,7 +221,12 @@ func (d *decoder) decode() error {
 				if !v.IsValid() {
 					continue
 				}
-				err := unmarshalValue(tok, v)
+				var err error
+				if raw, ok := tok.(json.RawMessage); ok && hasMapSelection(v.Type()) {
+					err = unmarshalRaw(raw, v, d.opts)
+				} else {
+					err = unmarshalValue(tok, v)
+				}
 				if err != nil {
 					return err
 				}
@@ -215,11 +252,8 @@ func (d *decoder) decode() error {
 				// Find GraphQL fragments/embedded structs recursively, adding to frontier
 				// as new ones are discovered and exploring them further.
 				for len(frontier) > 0 {
-					v := frontier[0]
+					v := indirect(frontier[0])
 					frontier = frontier[1:]
-					for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
-						v = v.Elem()
-					}
 					if v.Kind() == reflect.Struct {
 						for i := 0; i < v.NumField(); i++ {
 							if isGraphQLFragment(v.Type().Field(i)) || v.Type().Field(i).Anonymous {
@@ -292,15 +326,37 @@ func (d *decoder) decode() error {
 	return nil
 }
 
+// selection mirrors the Selection type of the graphql package,
+// which is decoded as its ordered map.
+type selection interface {
+	Pairs() [][2]interface{}
+}
+
+var selectionInterface = reflect.TypeOf((*selection)(nil)).Elem()
+
+// isSelection reports whether v is a non-nil pointer to a selection.
+func isSelection(v reflect.Value) bool {
+	return v.Kind() == reflect.Ptr && !v.IsNil() && v.Type().Implements(selectionInterface)
+}
+
+// indirect dereferences the pointers and interfaces of v.
+// Selections are replaced by their ordered map.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if isSelection(v) {
+			return reflect.ValueOf(v.Interface().(selection).Pairs())
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
 func copyTemplate(template reflect.Value) (reflect.Value, error) {
 	if isOrderedMap(template) {
 		// copy slice if it's actually an ordered map
 		return copyOrderedMap(template), nil
 	}
-	if template.Kind() == reflect.Map {
-		return reflect.Value{}, fmt.Errorf("unsupported template type `%v`, use [][2]interface{} for ordered map instead", template.Type())
-	}
-	// don't need to copy regular slice
+	// don't need to copy regular slice, nor map, which is replaced when decoded
 	return template, nil
 }
 
@@ -358,7 +414,10 @@ func (d *decoder) popAllVs() {
 func (d *decoder) popLeftArrayTemplates() {
 	for i := range d.vs {
 		v := d.vs[i].Top()
-		if v.IsValid() {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			v = v.Elem()
+		}
+		if v.Kind() == reflect.Slice {
 			v.Set(v.Slice(1, v.Len()))
 		}
 	}
@@ -366,14 +425,15 @@ func (d *decoder) popLeftArrayTemplates() {
 
 // fieldByGraphQLName returns an exported struct field of struct v
 // that matches GraphQL name, or invalid reflect.Value if none found.
-func fieldByGraphQLName(v reflect.Value, name string) (val reflect.Value, taggedAsScalar bool) {
+// The names of untagged fields are converted and scalar types are detected according to opts.
+func fieldByGraphQLName(v reflect.Value, name string, opts Options) (val reflect.Value, taggedAsScalar bool) {
 	for i := 0; i < v.NumField(); i++ {
 		if v.Type().Field(i).PkgPath != "" {
 			// Skip unexported field.
 			continue
 		}
-		if hasGraphQLName(v.Type().Field(i), name) {
-			return v.Field(i), hasScalarTag(v.Type().Field(i))
+		if hasGraphQLName(v.Type().Field(i), name, opts.FieldName) {
+			return v.Field(i), hasScalarTag(v.Type().Field(i)) || isScalarType(v.Type().Field(i).Type, opts.IsScalar)
 		}
 	}
 	return reflect.Value{}, false
@@ -392,6 +452,152 @@ func orderedMapValueByGraphQLName(v reflect.Value, name string) reflect.Value {
 	return reflect.Value{}
 }
 
+// isUntyped reports whether the value v of an ordered map is an untyped destination,
+// i.e. nil or a pointer to an interface{}.
+func isUntyped(v reflect.Value) bool {
+	if v.Kind() != reflect.Interface {
+		return false
+	}
+	if v.IsNil() {
+		return true
+	}
+	t := v.Elem().Type()
+	return t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Interface
+}
+
+// isScalarType reports whether t, or the items of t if it's a list, implement
+// json.Unmarshaler or are reported as scalars by isScalar, which may be nil.
+// Such types are custom scalars that may be encoded as JSON objects or arrays,
+// so they are decoded as a whole. The query writer already treats them as
+// scalars, without a selection set, so their values aren't GraphQL objects.
+func isScalarType(t reflect.Type, isScalar func(reflect.Type) bool) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(jsonUnmarshaler) || (isScalar != nil && isScalar(t)) {
+		return true
+	}
+	if t.Kind() == reflect.Map && isInterface(t.Elem()) {
+		// Untyped JSON object.
+		return true
+	}
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		return isScalarType(t.Elem(), isScalar)
+	}
+	return false
+}
+
+var jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// isInterface reports whether t, or the type t points to, is an interface.
+func isInterface(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Interface
+}
+
+// hasMapSelection reports whether t, or the items of t if it's a list, is a map
+// whose keys select fields, i.e. a map with string keys and non-interface values.
+func hasMapSelection(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && !isInterface(t.Elem()) && !reflect.PtrTo(t).Implements(jsonUnmarshaler)
+	case reflect.Slice, reflect.Array:
+		return hasMapSelection(t.Elem())
+	}
+	return false
+}
+
+// unmarshalRaw decodes the JSON value data into v, which contains map selections.
+// The values of maps and items of lists are decoded by UnmarshalGraphQLWithOptions,
+// starting from their current value, so that nested templates are preserved.
+func unmarshalRaw(data json.RawMessage, v reflect.Value, opts Options) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		// Allocate a new value, because templates may share pointers.
+		p := reflect.New(v.Type().Elem())
+		if !v.IsNil() {
+			p.Elem().Set(v.Elem())
+		}
+		if err := unmarshalRaw(data, p.Elem(), opts); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	case reflect.Map:
+		return unmarshalMap(data, v, opts)
+	case reflect.Slice, reflect.Array:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		var template reflect.Value
+		if v.Len() > 0 {
+			template = v.Index(0)
+		}
+		list := v
+		if v.Kind() == reflect.Slice {
+			list = reflect.MakeSlice(v.Type(), len(items), len(items))
+		} else if len(items) > v.Len() {
+			return fmt.Errorf("cannot decode %d items into %v", len(items), v.Type())
+		}
+		for i, item := range items {
+			if template.IsValid() {
+				list.Index(i).Set(template)
+			}
+			if err := unmarshalRaw(item, list.Index(i), opts); err != nil {
+				return err
+			}
+		}
+		v.Set(list)
+		return nil
+	default:
+		return UnmarshalGraphQLWithOptions(data, v.Addr().Interface(), opts)
+	}
+}
+
+// unmarshalMap decodes the JSON object data into map v. Keys of v are GraphQL
+// selections, e.g. "u1: user(id: 1)", which are matched by their alias or name
+// and keep their value as template. The decoded map is keyed by the response
+// keys of the fields, e.g. "u1".
+func unmarshalMap(data json.RawMessage, v reflect.Value, opts Options) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	// Index the templates by response key, so that each field finds its own in constant time.
+	templates := make(map[string]reflect.Value, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		if name, ok := graphQLKeyName(iter.Key().String()); ok {
+			templates[name] = iter.Value()
+		}
+	}
+	t := v.Type()
+	m := reflect.MakeMapWithSize(t, len(fields))
+	for name, raw := range fields {
+		key := reflect.ValueOf(name).Convert(t.Key())
+		elem := reflect.New(t.Elem()).Elem()
+		if template, ok := templates[name]; ok {
+			elem.Set(template)
+		}
+		if err := unmarshalRaw(raw, elem, opts); err != nil {
+			return fmt.Errorf("failed to decode map key %q: %w", name, err)
+		}
+		m.SetMapIndex(key, elem)
+	}
+	v.Set(m)
+	return nil
+}
+
 func hasScalarTag(f reflect.StructField) bool {
 	return isTrue(f.Tag.Get("scalar"))
 }
@@ -402,9 +608,12 @@ func isTrue(s string) bool {
 }
 
 // hasGraphQLName reports whether struct field f has GraphQL name.
-func hasGraphQLName(f reflect.StructField, name string) bool {
+func hasGraphQLName(f reflect.StructField, name string, fieldName func(string) string) bool {
 	value, ok := f.Tag.Lookup("graphql")
 	if !ok {
+		if fieldName != nil {
+			return fieldName(f.Name) == name
+		}
 		// TODO: caseconv package is relatively slow. Optimize it, then consider using it here.
 		//return caseconv.MixedCapsToLowerCamelCase(f.Name) == name
 		return strings.EqualFold(f.Name, name)
@@ -413,18 +622,29 @@ func hasGraphQLName(f reflect.StructField, name string) bool {
 }
 
 func keyHasGraphQLName(value, name string) bool {
+	key, ok := graphQLKeyName(value)
+	return ok && key == name
+}
+
+// graphQLKeyName returns the response key of the field selected by value,
+// i.e. its alias, or its name if it has no alias. Fragments don't have a key.
+func graphQLKeyName(value string) (string, bool) {
 	value = strings.TrimSpace(value) // TODO: Parse better.
 	if strings.HasPrefix(value, "...") {
 		// GraphQL fragment. It doesn't have a name.
-		return false
+		return "", false
 	}
 	if i := strings.Index(value, "("); i != -1 {
 		value = value[:i]
 	}
+	if i := strings.Index(value, "@"); i != -1 {
+		// Directive without arguments.
+		value = value[:i]
+	}
 	if i := strings.Index(value, ":"); i != -1 {
 		value = value[:i]
 	}
-	return strings.TrimSpace(value) == name
+	return strings.TrimSpace(value), true
 }
 
 // isGraphQLFragment reports whether struct field f is a GraphQL fragment.
@@ -451,6 +671,18 @@ func unmarshalValue(value interface{}, v reflect.Value) error {
 		return err
 	}
 	ty := v.Type()
+	if ty.Kind() == reflect.Interface {
+		if e := v.Elem(); e.Kind() == reflect.Ptr && !e.IsNil() {
+			// Decode into the pointed value, which is the destination of
+			// an ordered map or selection field.
+			v = e.Elem()
+			ty = v.Type()
+			if ty.Kind() == reflect.Interface {
+				// Untyped destination, replace the value of the previous decoding.
+				v.Set(reflect.Zero(ty))
+			}
+		}
+	}
 	if ty.Kind() == reflect.Interface {
 		if !v.Elem().IsValid() {
 			return json.Unmarshal(b, v.Addr().Interface())
@@ -462,6 +694,46 @@ func unmarshalValue(value interface{}, v reflect.Value) error {
 	if err != nil {
 		return err
 	}
+	if s, ok := value.(string); ok {
+		if err := checkEnumValue(s, newVal.Elem()); err != nil {
+			return err
+		}
+	}
 	v.Set(newVal.Elem())
 	return nil
 }
+
+// enum mirrors the GraphQLEnum interface of the graphql package.
+type enum interface {
+	EnumValues() []string
+}
+
+// enumFallback mirrors the GraphQLEnumFallback interface of the graphql package.
+type enumFallback interface {
+	EnumFallback() string
+}
+
+// checkEnumValue checks that s is a value of v, if v is an enum or a pointer to one.
+// Unknown values are replaced by the fallback value of the enum, if any.
+func checkEnumValue(s string, v reflect.Value) error {
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.String {
+		return nil
+	}
+	e, ok := v.Interface().(enum)
+	if !ok {
+		return nil
+	}
+	for _, value := range e.EnumValues() {
+		if s == value {
+			return nil
+		}
+	}
+	if f, ok := v.Interface().(enumFallback); ok {
+		v.SetString(f.EnumFallback())
+		return nil
+	}
+	return fmt.Errorf("unknown value %q of enum %v", s, v.Type())
+}
@@ -2,6 +2,7 @@ package jsonutil_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
@@ -80,6 +81,84 @@ func TestUnmarshalGraphQL_jsonTag(t *testing.T) {
 	}
 }
 
+func TestUnmarshalGraphQLWithOptions_fieldName(t *testing.T) {
+	type query struct {
+		FirstName string
+		LastName  string `graphql:"surname"`
+	}
+	var got query
+	err := jsonutil.UnmarshalGraphQLWithOptions([]byte(`{
+		"first_name": "Gopher",
+		"surname": "Go"
+	}`), &got, jsonutil.Options{
+		FieldName: func(name string) string {
+			if name == "FirstName" {
+				return "first_name"
+			}
+			return name
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := query{
+		FirstName: "Gopher",
+		LastName:  "Go",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("not equal")
+	}
+}
+
+type color string
+
+func (color) EnumValues() []string { return []string{"RED", "BLUE"} }
+
+type fallbackColor string
+
+func (fallbackColor) EnumValues() []string { return []string{"RED", "BLUE"} }
+
+func (fallbackColor) EnumFallback() string { return "UNKNOWN" }
+
+func TestUnmarshalGraphQL_enum(t *testing.T) {
+	type query struct {
+		Color     color
+		Colors    []*color
+		Optional  *color
+		Fallbacks []fallbackColor
+		Nullable  *fallbackColor
+	}
+	var got query
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"color": "RED",
+		"colors": ["BLUE", null],
+		"optional": null,
+		"fallbacks": ["BLUE", "GREEN"],
+		"nullable": "GREEN"
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blue := color("BLUE")
+	unknown := fallbackColor("UNKNOWN")
+	want := query{
+		Color:     "RED",
+		Colors:    []*color{&blue, nil},
+		Fallbacks: []fallbackColor{"BLUE", "UNKNOWN"},
+		Nullable:  &unknown,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not equal: %v %v", want, got)
+	}
+
+	for _, data := range []string{`{"color": "GREEN"}`, `{"optional": "GREEN"}`} {
+		err = jsonutil.UnmarshalGraphQL([]byte(data), &got)
+		if got, want := fmt.Sprint(err), `unknown value "GREEN" of enum jsonutil_test.color`; got != want {
+			t.Errorf("%s: got error: %v, want: %v", data, got, want)
+		}
+	}
+}
+
 func TestUnmarshalGraphQL_jsonRawTag(t *testing.T) {
 	type query struct {
 		Data    json.RawMessage
@@ -139,6 +218,69 @@ func TestUnmarshalGraphQL_fieldAsScalar(t *testing.T) {
 	}
 }
 
+// jsonScalar is a custom scalar that is encoded as any JSON value.
+type jsonScalar struct {
+	raw string
+}
+
+func (j *jsonScalar) UnmarshalJSON(data []byte) error {
+	j.raw = string(data)
+	return nil
+}
+
+func TestUnmarshalGraphQL_jsonUnmarshaler(t *testing.T) {
+	type query struct {
+		Object jsonScalar
+		List   []*jsonScalar
+		At     time.Time
+	}
+	var got query
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"object": {"a": [1, 2]},
+		"list": [{"b": true}, [3]],
+		"at": "2022-03-04T05:06:07Z"
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := query{
+		Object: jsonScalar{`{"a":[1,2]}`},
+		List:   []*jsonScalar{{`{"b":true}`}, {`[3]`}},
+		At:     time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not equal: %v %v", want, got)
+	}
+}
+
+func TestUnmarshalGraphQLWithOptions_isScalar(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	type query struct {
+		Center   point
+		Vertices []*point
+	}
+	pointType := reflect.TypeOf(point{})
+	var got query
+	err := jsonutil.UnmarshalGraphQLWithOptions([]byte(`{
+		"center": {"x": 1, "y": 2},
+		"vertices": [{"x": 3, "y": 4}, null]
+	}`), &got, jsonutil.Options{
+		IsScalar: func(t reflect.Type) bool { return t == pointType },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := query{
+		Center:   point{1, 2},
+		Vertices: []*point{{3, 4}, nil},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not equal: %v %v", want, got)
+	}
+}
+
 func TestUnmarshalGraphQL_orderedMap(t *testing.T) {
 	type query [][2]interface{}
 	got := query{
@@ -625,3 +767,68 @@ func TestUnmarshalGraphQL_arrayInsideInlineFragment(t *testing.T) {
 		t.Error("not equal")
 	}
 }
+
+func TestUnmarshalGraphQL_map(t *testing.T) {
+	type user struct {
+		Login string
+		Meta  map[string]interface{}
+	}
+	type query struct {
+		Nodes map[string]*user
+		Lists []map[string]user
+	}
+	template := &user{}
+	got := query{
+		Nodes: map[string]*user{
+			"a: node(id: $a)": template,
+			"b: node(id: $b)": template,
+		},
+		Lists: []map[string]user{{"first: user": {}}},
+	}
+	err := jsonutil.UnmarshalGraphQL([]byte(`{
+		"nodes": {
+			"a": {"login": "gopher", "meta": {"tags": ["go"], "stars": 5}},
+			"b": null,
+			"c": {"login": "extra"}
+		},
+		"lists": [{"first": {"login": "x", "meta": null}}, {}]
+	}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := query{
+		Nodes: map[string]*user{
+			"a": {Login: "gopher", Meta: map[string]interface{}{"tags": []interface{}{"go"}, "stars": 5.0}},
+			"b": nil,
+			"c": {Login: "extra"},
+		},
+		Lists: []map[string]user{{"first": {Login: "x"}}, {}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not equal:\n got: %#v\nwant: %#v", got, want)
+	}
+	if !reflect.DeepEqual(*template, user{}) {
+		t.Errorf("template was modified: %v", template)
+	}
+}
+
+func TestUnmarshalGraphQL_topLevelMap(t *testing.T) {
+	type user struct {
+		Login string `graphql:"login"`
+	}
+	got := map[string]user{
+		"u0: user(login: \"a\")": {},
+		"u1: user(login: \"b\")": {},
+	}
+	err := jsonutil.UnmarshalGraphQL([]byte(`{"u0": {"login": "a"}, "u1": {"login": "b"}}`), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]user{
+		"u0": {Login: "a"},
+		"u1": {Login: "b"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("not equal:\n got: %v\nwant: %v", got, want)
+	}
+}
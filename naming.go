@@ -0,0 +1,65 @@
+package graphql
+
+import (
+	"github.com/zainirfan13/graphql-client/ident"
+)
+
+// NamingStrategy converts the name of a Go struct field that doesn't have
+// a graphql tag into the GraphQL field name. The same strategy is used to
+// build the query and to match response fields while decoding, so both
+// sides always agree.
+type NamingStrategy func(fieldName string) string
+
+// LowerCamelCaseNaming is the default naming strategy.
+//
+// E.g., "DatabaseID" -> "databaseId".
+func LowerCamelCaseNaming(fieldName string) string {
+	return ident.ParseMixedCaps(fieldName).ToLowerCamelCase()
+}
+
+// SnakeCaseNaming converts field names to snake_case,
+// which is used by Hasura and PostGraphile schemas.
+//
+// E.g., "DatabaseID" -> "database_id".
+func SnakeCaseNaming(fieldName string) string {
+	return ident.ParseMixedCaps(fieldName).ToSnakeCase()
+}
+
+// LowerCamelCaseNamingWith returns a lowerCamelCase naming strategy
+// that parses field names with the initialisms and digit handling of namer.
+func LowerCamelCaseNamingWith(namer *ident.Namer) NamingStrategy {
+	return func(fieldName string) string {
+		return namer.ParseMixedCaps(fieldName).ToLowerCamelCase()
+	}
+}
+
+// SnakeCaseNamingWith returns a snake_case naming strategy
+// that parses field names with the initialisms and digit handling of namer.
+//
+// E.g., "AddressLine1" -> "address_line_1" if namer splits digits.
+func SnakeCaseNamingWith(namer *ident.Namer) NamingStrategy {
+	return func(fieldName string) string {
+		return namer.ParseMixedCaps(fieldName).ToSnakeCase()
+	}
+}
+
+// fieldNamingOption represents the naming strategy option
+type fieldNamingOption struct {
+	strategy NamingStrategy
+}
+
+func (fno fieldNamingOption) Type() OptionType {
+	return optionTypeFieldNaming
+}
+
+// String returns an empty string because the option isn't rendered into the query
+func (fno fieldNamingOption) String() string {
+	return ""
+}
+
+// FieldNaming creates the option that sets the naming strategy
+// of untagged struct fields for a single operation.
+// It overrides the strategy of the client.
+func FieldNaming(strategy NamingStrategy) Option {
+	return fieldNamingOption{strategy}
+}
@@ -0,0 +1,117 @@
+package graphql
+
+import (
+	"reflect"
+)
+
+// Omittable is an input value that tells an explicit null apart from an absent value,
+// which pointers can't do. It's useful for the fields of update mutation inputs,
+// where null clears a field and an absent value leaves it unchanged.
+//
+// The zero value is omitted: fields of input-object structs and values of maps
+// are left out of the request, and so are variables. Use Null for an explicit
+// null and Present for a value.
+//
+//	type UpdateUserInput struct {
+//		ID    graphql.ID        `json:"id"`
+//		Name  graphql.Omittable `json:"name"`
+//		Email graphql.Omittable `json:"email"`
+//	}
+//
+//	input := UpdateUserInput{ID: "1", Name: graphql.Null()}
+//	// {"id":"1","name":null}
+//
+// As a variable, an Omittable is declared as nullable with the GraphQL type
+// of the value passed to Present, or of the value passed to NullOf or OmittedOf,
+// which declare the type of null and omitted variables.
+type Omittable struct {
+	value   interface{}
+	typ     reflect.Type // The type of the variable, if set by NullOf or OmittedOf.
+	present bool
+}
+
+// Present returns an Omittable with value v. A nil v, or a nil pointer, is sent as null,
+// but unlike Null, a typed nil pointer keeps the type of the variable, e.g. (*string)(nil).
+func Present(v interface{}) Omittable {
+	return Omittable{value: v, present: true}
+}
+
+// Null returns an Omittable that is sent as an explicit null.
+func Null() Omittable {
+	return Omittable{present: true}
+}
+
+// NullOf returns an Omittable that is sent as an explicit null, and declared
+// as a variable with the type of v, e.g. NullOf("") is declared as String.
+func NullOf(v interface{}) Omittable {
+	return Omittable{typ: reflect.TypeOf(v), present: true}
+}
+
+// OmittedOf returns an Omittable that is left out of the request, and declared
+// as a variable with the type of v, e.g. OmittedOf(0) is declared as Int.
+func OmittedOf(v interface{}) Omittable {
+	return Omittable{typ: reflect.TypeOf(v)}
+}
+
+// IsOmitted reports whether o is left out of the request.
+func (o Omittable) IsOmitted() bool {
+	return !o.present
+}
+
+// IsNull reports whether o is sent as null.
+func (o Omittable) IsNull() bool {
+	if !o.present || o.value == nil {
+		return o.present
+	}
+	v := reflect.ValueOf(o.value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
+
+// Value returns the value of o, or nil if it's omitted.
+func (o Omittable) Value() interface{} {
+	return o.value
+}
+
+// MarshalJSON encodes the value of o. Omitted values are encoded as null,
+// since they can only be left out by the enclosing object.
+func (o Omittable) MarshalJSON() ([]byte, error) {
+	return marshalInput(reflect.ValueOf(o.value))
+}
+
+// graphQLType returns the Go type of the variable of o, or nil if it's unknown.
+func (o Omittable) graphQLType() reflect.Type {
+	if o.typ != nil {
+		return o.typ
+	}
+	return reflect.TypeOf(o.value)
+}
+
+// asOmittable returns the Omittable of value, if value is an Omittable.
+func asOmittable(value interface{}) (Omittable, bool) {
+	switch o := value.(type) {
+	case Omittable:
+		return o, true
+	case *Omittable:
+		if o != nil {
+			return *o, true
+		}
+	}
+	return Omittable{}, false
+}
+
+// isOmitted reports whether v holds an omitted Omittable.
+func isOmitted(v reflect.Value) bool {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.Type() != omittableType {
+		return false
+	}
+	return v.Interface().(Omittable).IsOmitted()
+}
+
+var omittableType = reflect.TypeOf(Omittable{})
@@ -0,0 +1,151 @@
+package graphql
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type updateUserInput struct {
+	ID       ID        `json:"id"`
+	Name     Omittable `graphql:"name"`
+	Email    Omittable `json:"email"`
+	Nickname Omittable `json:"nickname,omitempty"`
+	Address  *updateAddressInput
+}
+
+type updateAddressInput struct {
+	City Omittable `json:"city"`
+	Zip  Omittable `json:"zip"`
+}
+
+func TestOmittable(t *testing.T) {
+	tests := []struct {
+		in          Omittable
+		wantOmitted bool
+		wantNull    bool
+		wantJSON    string
+	}{
+		{in: Omittable{}, wantOmitted: true, wantJSON: `null`},
+		{in: Null(), wantNull: true, wantJSON: `null`},
+		{in: Present(nil), wantNull: true, wantJSON: `null`},
+		{in: Present((*string)(nil)), wantNull: true, wantJSON: `null`},
+		{in: Present(""), wantJSON: `""`},
+		{in: Present(updateAddressInput{City: Present("Paris")}), wantJSON: `{"city":"Paris"}`},
+	}
+	for i, tc := range tests {
+		if got := tc.in.IsOmitted(); got != tc.wantOmitted {
+			t.Errorf("test case %d: got IsOmitted %v, want %v", i, got, tc.wantOmitted)
+		}
+		if got := tc.in.IsNull(); got != tc.wantNull {
+			t.Errorf("test case %d: got IsNull %v, want %v", i, got, tc.wantNull)
+		}
+		got, err := json.Marshal(tc.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tc.wantJSON {
+			t.Errorf("test case %d: got JSON %s, want %s", i, got, tc.wantJSON)
+		}
+	}
+}
+
+func TestRequestVariables_omittable(t *testing.T) {
+	variables := map[string]interface{}{
+		"input": updateUserInput{
+			ID:    "1",
+			Name:  Present("Gopher"),
+			Email: Null(),
+			Address: &updateAddressInput{
+				Zip: Null(),
+			},
+		},
+		"patch": map[string]interface{}{
+			"name":  Omittable{},
+			"email": Present("gopher@example.com"),
+		},
+		"list":    []Omittable{{}, Present(1)},
+		"omitted": Omittable{},
+		"var":     Var{Value: Omittable{}, Type: "String"},
+		"null":    Null(),
+	}
+	got, err := requestVariables(variables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"input":{"id":"1","name":"Gopher","email":null,"Address":{"zip":null}},"list":[null,1],"null":null,"patch":{"email":"gopher@example.com"}}`; string(b) != want {
+		t.Errorf("\n got: %s\nwant: %s", b, want)
+	}
+}
+
+func TestConstructMutation_omittable(t *testing.T) {
+	var m struct {
+		UpdateUser struct {
+			ID ID
+		} `graphql:"updateUser(id: $id, name: $name, state: $state, input: $input)"`
+	}
+	variables := map[string]interface{}{
+		"id":    Present(ID("1")),
+		"name":  Present((*string)(nil)),
+		"state": Present(Color("GREEN")),
+		"input": updateUserInput{},
+	}
+	if _, err := ConstructMutation(&m, variables); err == nil || err.Error() != `invalid variable $state: value "GREEN" is not allowed by enum graphql.Color` {
+		t.Errorf("got error: %v, want invalid enum value", err)
+	}
+
+	variables["state"] = Present(ColorRed)
+	got, err := ConstructMutation(&m, variables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `mutation ($id:ID$input:updateUserInput!$name:String$state:Color){updateUser(id: $id, name: $name, state: $state, input: $input){id}}`; got != want {
+		t.Errorf("\n got: %q\nwant: %q", got, want)
+	}
+
+	variables["name"] = Null()
+	if _, err := ConstructMutation(&m, variables); err == nil || err.Error() != "invalid variable $name: type can't be derived from an omitted or null value, use Present with a typed value, NullOf, OmittedOf or Var" {
+		t.Errorf("got error: %v, want type can't be derived", err)
+	}
+	variables["name"] = Var{Value: Null(), Type: "String"}
+	if _, err := ConstructMutation(&m, variables); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOmittable_typedVariables(t *testing.T) {
+	var m struct {
+		UpdateUser struct {
+			ID ID
+		} `graphql:"updateUser(id: $id, name: $name, age: $age)"`
+	}
+	variables := map[string]interface{}{
+		"id":   ID("1"),
+		"name": NullOf(""),
+		"age":  OmittedOf(0),
+	}
+	got, err := ConstructMutation(&m, variables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `mutation ($age:Int$id:ID!$name:String){updateUser(id: $id, name: $name, age: $age){id}}`; got != want {
+		t.Errorf("\n got: %q\nwant: %q", got, want)
+	}
+	encoded, err := requestVariables(variables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"id":"1","name":null}`; string(b) != want {
+		t.Errorf("\n got: %s\nwant: %s", b, want)
+	}
+	if !NullOf("").IsNull() || !OmittedOf(0).IsOmitted() {
+		t.Error("got NullOf not null or OmittedOf not omitted")
+	}
+}
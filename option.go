@@ -7,6 +7,14 @@ const (
 	// optionTypeOperationName is private because it's option is built-in and unique
 	optionTypeOperationName      OptionType = "operation_name"
 	OptionTypeOperationDirective OptionType = "operation_directive"
+	// optionTypeFieldNaming is private because it doesn't render anything into the query
+	optionTypeFieldNaming OptionType = "field_naming"
+	// optionTypeScalarTypes is private because it doesn't render anything into the query
+	optionTypeScalarTypes OptionType = "scalar_types"
+	// optionTypeFetchPolicy is private because it doesn't render anything into the query
+	optionTypeFetchPolicy OptionType = "fetch_policy"
+	// optionTypeVariables is private because its variables are merged into the variables of the operation
+	optionTypeVariables OptionType = "variables"
 )
 
 // Option abstracts an extra render interface for the query string
@@ -36,3 +44,14 @@ func (ono operationNameOption) String() string {
 func OperationName(name string) Option {
 	return operationNameOption{name}
 }
+
+// operationNameOf returns the name set by the last OperationName option, if any.
+func operationNameOf(options []Option) string {
+	name := ""
+	for _, option := range options {
+		if option.Type() == optionTypeOperationName {
+			name = option.String()
+		}
+	}
+	return name
+}
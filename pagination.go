@@ -0,0 +1,222 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrPaginationStopped is a special error which stops the pagination without error.
+// The function passed to Paginate can return it, or an error wrapping it.
+var ErrPaginationStopped = errors.New("pagination stopped")
+
+// PageInfo is the page information of Relay connections.
+// Connections can use it, or any struct with the same fields.
+type PageInfo struct {
+	HasNextPage     bool
+	HasPreviousPage bool
+	StartCursor     *string
+	EndCursor       *string
+}
+
+// Pagination configures Paginate.
+type Pagination struct {
+	// Cursor is the name of the cursor variable,
+	// "after" by default, or "before" when Backward is set.
+	Cursor string
+	// PageSize is the number of nodes of each page, passed in the page size variable.
+	// If it's zero, the page size variable is left unchanged.
+	PageSize int
+	// PageSizeVariable is the name of the page size variable,
+	// "first" by default, or "last" when Backward is set.
+	PageSizeVariable string
+	// MaxPages is the maximum number of pages to fetch, or zero for no limit.
+	MaxPages int
+	// Backward pages from the end of the connection, passing the start cursor
+	// of each page while it has a previous page.
+	Backward bool
+}
+
+// Paginate runs the query q for each page of a Relay connection, and calls fn with each node.
+//
+// q must be a pointer to struct that contains a connection, i.e. a struct with a PageInfo field
+// and either a Nodes field, or an Edges field whose items have a Node field. The first connection
+// found in q is used. The query declares the cursor variable, e.g.
+//
+//	var q struct {
+//		Repository struct {
+//			Issues struct {
+//				Nodes    []struct{ Title string }
+//				PageInfo graphql.PageInfo
+//			} `graphql:"issues(first: $first, after: $after)"`
+//		} `graphql:"repository(owner: \"octocat\", name: \"Hello-World\")"`
+//	}
+//	err := client.Paginate(ctx, &q, nil, graphql.Pagination{PageSize: 50}, func(node interface{}) error {
+//		fmt.Println(node.(*struct{ Title string }).Title)
+//		return nil
+//	})
+//
+// node is a pointer to the item of the current page in q, which holds the last page
+// when Paginate returns, so pass a fresh query to paginate again. The cursor variable is sent
+// as a nullable String, unless variables has a Var for it, whose type is kept. It's omitted for the first page,
+// unless variables sets a starting cursor, as a value or the Value of the Var, e.g. to resume a crawl.
+//
+// Paginate stops at the last page, after MaxPages, when ctx is done, or when fn returns an error.
+// fn can return ErrPaginationStopped to stop without error.
+func (c *Client) Paginate(ctx context.Context, q interface{}, variables map[string]interface{}, p Pagination, fn func(node interface{}) error, options ...Option) error {
+	rv := reflect.ValueOf(q)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("query must be a non-nil pointer to struct, got %T", q)
+	}
+	cursorName, pageSizeName := p.Cursor, p.PageSizeVariable
+	if cursorName == "" {
+		cursorName = "after"
+		if p.Backward {
+			cursorName = "before"
+		}
+	}
+	if pageSizeName == "" {
+		pageSizeName = "first"
+		if p.Backward {
+			pageSizeName = "last"
+		}
+	}
+
+	vars := make(map[string]interface{}, len(variables)+2)
+	for name, value := range variables {
+		vars[name] = value
+	}
+	cursor, ok := asVar(vars[cursorName])
+	if !ok && vars[cursorName] != nil {
+		cursor.Value = vars[cursorName]
+	}
+	if cursor.Type == "" {
+		cursor.Type = "String"
+	}
+	vars[cursorName] = cursor
+	if p.PageSize > 0 {
+		vars[pageSizeName] = p.PageSize
+	}
+
+	// The query is reset before each page, since the decoder
+	// can't decode into slices that have more than one template item.
+	template := reflect.New(rv.Elem().Type()).Elem()
+	template.Set(rv.Elem())
+	for page := 0; p.MaxPages <= 0 || page < p.MaxPages; page++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rv.Elem().Set(template)
+		if err := c.Query(ctx, q, vars, options...); err != nil {
+			return err
+		}
+		conn, ok := findConnection(rv.Elem())
+		if !ok {
+			return fmt.Errorf("no connection with PageInfo and Nodes or Edges found in %T", q)
+		}
+		for _, node := range conn.nodes() {
+			if err := fn(node.Addr().Interface()); err != nil {
+				if errors.Is(err, ErrPaginationStopped) {
+					return nil
+				}
+				return err
+			}
+		}
+
+		hasMore, next := conn.next(p.Backward)
+		if !hasMore || next == "" {
+			return nil
+		}
+		cursor.Value = next
+		vars[cursorName] = cursor
+	}
+	return nil
+}
+
+// connection is a decoded Relay connection.
+type connection struct {
+	pageInfo  reflect.Value
+	nodeSlice reflect.Value
+	edgeSlice reflect.Value
+}
+
+// nodes returns the addressable nodes of the connection.
+func (c connection) nodes() []reflect.Value {
+	var nodes []reflect.Value
+	if c.nodeSlice.IsValid() {
+		for i := 0; i < c.nodeSlice.Len(); i++ {
+			nodes = append(nodes, c.nodeSlice.Index(i))
+		}
+		return nodes
+	}
+	for i := 0; i < c.edgeSlice.Len(); i++ {
+		edge := indirectValue(c.edgeSlice.Index(i))
+		if !edge.IsValid() {
+			continue
+		}
+		nodes = append(nodes, edge.FieldByName("Node"))
+	}
+	return nodes
+}
+
+// next reports whether there is a next page and returns its cursor.
+func (c connection) next(backward bool) (bool, string) {
+	hasMore, cursor := "HasNextPage", "EndCursor"
+	if backward {
+		hasMore, cursor = "HasPreviousPage", "StartCursor"
+	}
+	h := indirectValue(c.pageInfo.FieldByName(hasMore))
+	v := indirectValue(c.pageInfo.FieldByName(cursor))
+	if !h.IsValid() || h.Kind() != reflect.Bool || !v.IsValid() || v.Kind() != reflect.String {
+		return false, ""
+	}
+	return h.Bool(), v.String()
+}
+
+// findConnection returns the first connection found in v, depth first.
+func findConnection(v reflect.Value) (connection, bool) {
+	v = indirectValue(v)
+	if !v.IsValid() || v.Kind() != reflect.Struct {
+		return connection{}, false
+	}
+	if pageInfo := indirectValue(v.FieldByName("PageInfo")); pageInfo.IsValid() && pageInfo.Kind() == reflect.Struct {
+		if nodes := v.FieldByName("Nodes"); nodes.IsValid() && nodes.Kind() == reflect.Slice {
+			return connection{pageInfo: pageInfo, nodeSlice: nodes}, true
+		}
+		if edges := v.FieldByName("Edges"); edges.IsValid() && edges.Kind() == reflect.Slice && hasNodeField(edges.Type().Elem()) {
+			return connection{pageInfo: pageInfo, edgeSlice: edges}, true
+		}
+	}
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).PkgPath != "" {
+			continue
+		}
+		if conn, ok := findConnection(v.Field(i)); ok {
+			return conn, true
+		}
+	}
+	return connection{}, false
+}
+
+func hasNodeField(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	_, ok := t.FieldByName("Node")
+	return ok
+}
+
+// indirectValue dereferences pointers of v, returning an invalid value for nil pointers.
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
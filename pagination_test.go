@@ -0,0 +1,158 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/zainirfan13/graphql-client"
+)
+
+func TestClient_Paginate(t *testing.T) {
+	pages := []string{
+		`{"data": {"repository": {"issues": {"nodes": [{"title": "a"}, {"title": "b"}], "pageInfo": {"hasNextPage": true, "hasPreviousPage": false, "startCursor": "c1", "endCursor": "c2"}}}}}`,
+		`{"data": {"repository": {"issues": {"nodes": [{"title": "c"}], "pageInfo": {"hasNextPage": false, "hasPreviousPage": true, "startCursor": "c3", "endCursor": "c3"}}}}}`,
+	}
+	wantBodies := []string{
+		`{"query":"query ($after:String$first:Int!){repository(owner: \"octocat\", name: \"Hello-World\"){issues(first: $first, after: $after){nodes{title},pageInfo{hasNextPage,hasPreviousPage,startCursor,endCursor}}}}","variables":{"first":2}}` + "\n",
+		`{"query":"query ($after:String$first:Int!){repository(owner: \"octocat\", name: \"Hello-World\"){issues(first: $first, after: $after){nodes{title},pageInfo{hasNextPage,hasPreviousPage,startCursor,endCursor}}}}","variables":{"after":"c2","first":2}}` + "\n",
+	}
+	page := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		if page >= len(pages) {
+			t.Fatalf("unexpected request for page %d", page)
+		}
+		if got := mustRead(req.Body); got != wantBodies[page] {
+			t.Errorf("page %d: got body: %v, want %v", page, got, wantBodies[page])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, pages[page])
+		page++
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	type issue struct{ Title string }
+	type query struct {
+		Repository struct {
+			Issues struct {
+				Nodes    []issue
+				PageInfo graphql.PageInfo
+			} `graphql:"issues(first: $first, after: $after)"`
+		} `graphql:"repository(owner: \"octocat\", name: \"Hello-World\")"`
+	}
+	var q query
+	var titles []string
+	err := client.Paginate(context.Background(), &q, nil, graphql.Pagination{PageSize: 2}, func(node interface{}) error {
+		titles = append(titles, node.(*issue).Title)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(titles), "[a b c]"; got != want {
+		t.Errorf("got titles: %v, want: %v", got, want)
+	}
+
+	// Stopped by the callback on the first page.
+	page, titles, q = 0, nil, query{}
+	err = client.Paginate(context.Background(), &q, nil, graphql.Pagination{PageSize: 2}, func(node interface{}) error {
+		titles = append(titles, node.(*issue).Title)
+		return graphql.ErrPaginationStopped
+	})
+	if err != nil || len(titles) != 1 || page != 1 {
+		t.Errorf("got error: %v, titles: %v, pages: %d, want stop after the first node", err, titles, page)
+	}
+
+	// Stopped by a wrapped error.
+	page, titles, q = 0, nil, query{}
+	err = client.Paginate(context.Background(), &q, nil, graphql.Pagination{PageSize: 2}, func(node interface{}) error {
+		titles = append(titles, node.(*issue).Title)
+		return fmt.Errorf("found %q: %w", node.(*issue).Title, graphql.ErrPaginationStopped)
+	})
+	if err != nil || len(titles) != 1 || page != 1 {
+		t.Errorf("got error: %v, titles: %v, pages: %d, want stop after the first node", err, titles, page)
+	}
+
+	// Limited by MaxPages.
+	page, titles, q = 0, nil, query{}
+	err = client.Paginate(context.Background(), &q, nil, graphql.Pagination{PageSize: 2, MaxPages: 1}, func(node interface{}) error {
+		titles = append(titles, node.(*issue).Title)
+		return nil
+	})
+	if err != nil || len(titles) != 2 || page != 1 {
+		t.Errorf("got error: %v, titles: %v, pages: %d, want only the first page", err, titles, page)
+	}
+
+	// Resumed from a cursor, as a value or a Var.
+	for _, cursor := range []interface{}{"c2", graphql.Var{Value: "c2"}} {
+		page, titles, q = 1, nil, query{}
+		err = client.Paginate(context.Background(), &q, map[string]interface{}{"after": cursor}, graphql.Pagination{PageSize: 2}, func(node interface{}) error {
+			titles = append(titles, node.(*issue).Title)
+			return nil
+		})
+		if err != nil || fmt.Sprint(titles) != "[c]" || page != 2 {
+			t.Errorf("got error: %v, titles: %v, pages: %d, want only the second page", err, titles, page)
+		}
+	}
+
+	// Canceled context.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	page, q = 0, query{}
+	err = client.Paginate(ctx, &q, nil, graphql.Pagination{}, func(node interface{}) error { return nil })
+	if !errors.Is(err, context.Canceled) || page != 0 {
+		t.Errorf("got error: %v, pages: %d, want context.Canceled", err, page)
+	}
+}
+
+func TestClient_Paginate_backwardEdges(t *testing.T) {
+	pages := []string{
+		`{"data": {"viewer": {"starredRepositories": {"edges": [{"node": {"name": "c"}}], "pageInfo": {"hasPreviousPage": true, "startCursor": "s1"}}}}}`,
+		`{"data": {"viewer": {"starredRepositories": {"edges": [{"node": {"name": "a"}}, {"node": {"name": "b"}}], "pageInfo": {"hasPreviousPage": false, "startCursor": "s0"}}}}}`,
+	}
+	wantBodies := []string{
+		`{"query":"query ($before:Cursor$last:Int!){viewer{starredRepositories(last: $last, before: $before){edges{node{name}},pageInfo{hasPreviousPage,startCursor}}}}","variables":{"last":1}}` + "\n",
+		`{"query":"query ($before:Cursor$last:Int!){viewer{starredRepositories(last: $last, before: $before){edges{node{name}},pageInfo{hasPreviousPage,startCursor}}}}","variables":{"before":"s1","last":1}}` + "\n",
+	}
+	page := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		if got := mustRead(req.Body); got != wantBodies[page] {
+			t.Errorf("page %d: got body: %v, want %v", page, got, wantBodies[page])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, pages[page])
+		page++
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}})
+
+	type repository struct{ Name string }
+	var q struct {
+		Viewer struct {
+			StarredRepositories struct {
+				Edges []struct {
+					Node repository
+				}
+				PageInfo struct {
+					HasPreviousPage bool
+					StartCursor     string
+				}
+			} `graphql:"starredRepositories(last: $last, before: $before)"`
+		}
+	}
+	var names []string
+	variables := map[string]interface{}{"before": graphql.Var{Type: "Cursor"}}
+	err := client.Paginate(context.Background(), &q, variables, graphql.Pagination{PageSize: 1, Backward: true}, func(node interface{}) error {
+		names = append(names, node.(*repository).Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(names), "[c a b]"; got != want {
+		t.Errorf("got names: %v, want: %v", got, want)
+	}
+}
@@ -9,13 +9,13 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-
-	"github.com/zainirfan13/graphql-client/ident"
 )
 
 type constructOptionsOutput struct {
 	operationName       string
 	operationDirectives []string
+	namingStrategy      NamingStrategy
+	scalarTypes         scalarTypeLayers
 }
 
 func (coo constructOptionsOutput) OperationDirectivesString() string {
@@ -26,6 +26,19 @@ func (coo constructOptionsOutput) OperationDirectivesString() string {
 	return ""
 }
 
+// fieldName returns the GraphQL name of an untagged struct field
+func (coo constructOptionsOutput) fieldName(name string) string {
+	if coo.namingStrategy != nil {
+		return coo.namingStrategy(name)
+	}
+	return LowerCamelCaseNaming(name)
+}
+
+// scalarName returns the GraphQL name of t if it's a registered scalar type
+func (coo constructOptionsOutput) scalarName(t reflect.Type) (string, bool) {
+	return coo.scalarTypes.lookup(t)
+}
+
 func constructOptions(options []Option) (*constructOptionsOutput, error) {
 	output := &constructOptionsOutput{}
 
@@ -35,6 +48,20 @@ func constructOptions(options []Option) (*constructOptionsOutput, error) {
 			output.operationName = option.String()
 		case OptionTypeOperationDirective:
 			output.operationDirectives = append(output.operationDirectives, option.String())
+		case optionTypeFieldNaming:
+			fno, ok := option.(fieldNamingOption)
+			if !ok {
+				return nil, fmt.Errorf("invalid field naming option: %T", option)
+			}
+			output.namingStrategy = fno.strategy
+		case optionTypeScalarTypes:
+			sto, ok := option.(scalarTypesOption)
+			if !ok {
+				return nil, fmt.Errorf("invalid scalar types option: %T", option)
+			}
+			output.scalarTypes = append(output.scalarTypes, sto.types)
+		case optionTypeFetchPolicy:
+			// The fetch policy is read by the client.
 		default:
 			return nil, fmt.Errorf("invalid query option type: %s", option.Type())
 		}
@@ -45,18 +72,30 @@ func constructOptions(options []Option) (*constructOptionsOutput, error) {
 
 // ConstructQuery build GraphQL query string from struct and variables
 func ConstructQuery(v interface{}, variables map[string]interface{}, options ...Option) (string, error) {
-	query, err := query(v)
+	variables, options, err := withOptionVariables(variables, options, nil)
 	if err != nil {
 		return "", err
 	}
-
 	optionsOutput, err := constructOptions(options)
 	if err != nil {
 		return "", err
 	}
 
+	if err := validateVariables(variables); err != nil {
+		return "", err
+	}
+
+	query, err := query(v, optionsOutput)
+	if err != nil {
+		return "", err
+	}
+
 	if len(variables) > 0 {
-		return fmt.Sprintf("query %s(%s)%s%s", optionsOutput.operationName, queryArguments(variables), optionsOutput.OperationDirectivesString(), query), nil
+		arguments, err := queryArguments(variables, optionsOutput)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("query %s(%s)%s%s", optionsOutput.operationName, arguments, optionsOutput.OperationDirectivesString(), query), nil
 	}
 
 	if optionsOutput.operationName == "" && len(optionsOutput.operationDirectives) == 0 {
@@ -68,7 +107,7 @@ func ConstructQuery(v interface{}, variables map[string]interface{}, options ...
 
 // ConstructQuery build GraphQL mutation string from struct and variables
 func ConstructMutation(v interface{}, variables map[string]interface{}, options ...Option) (string, error) {
-	query, err := query(v)
+	variables, options, err := withOptionVariables(variables, options, nil)
 	if err != nil {
 		return "", err
 	}
@@ -76,8 +115,19 @@ func ConstructMutation(v interface{}, variables map[string]interface{}, options
 	if err != nil {
 		return "", err
 	}
+	if err := validateVariables(variables); err != nil {
+		return "", err
+	}
+	query, err := query(v, optionsOutput)
+	if err != nil {
+		return "", err
+	}
 	if len(variables) > 0 {
-		return fmt.Sprintf("mutation %s(%s)%s%s", optionsOutput.operationName, queryArguments(variables), optionsOutput.OperationDirectivesString(), query), nil
+		arguments, err := queryArguments(variables, optionsOutput)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("mutation %s(%s)%s%s", optionsOutput.operationName, arguments, optionsOutput.OperationDirectivesString(), query), nil
 	}
 
 	if optionsOutput.operationName == "" && len(optionsOutput.operationDirectives) == 0 {
@@ -89,7 +139,7 @@ func ConstructMutation(v interface{}, variables map[string]interface{}, options
 
 // ConstructSubscription build GraphQL subscription string from struct and variables
 func ConstructSubscription(v interface{}, variables map[string]interface{}, options ...Option) (string, error) {
-	query, err := query(v)
+	variables, options, err := withOptionVariables(variables, options, nil)
 	if err != nil {
 		return "", err
 	}
@@ -97,8 +147,19 @@ func ConstructSubscription(v interface{}, variables map[string]interface{}, opti
 	if err != nil {
 		return "", err
 	}
+	if err := validateVariables(variables); err != nil {
+		return "", err
+	}
+	query, err := query(v, optionsOutput)
+	if err != nil {
+		return "", err
+	}
 	if len(variables) > 0 {
-		return fmt.Sprintf("subscription %s(%s)%s%s", optionsOutput.operationName, queryArguments(variables), optionsOutput.OperationDirectivesString(), query), nil
+		arguments, err := queryArguments(variables, optionsOutput)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("subscription %s(%s)%s%s", optionsOutput.operationName, arguments, optionsOutput.OperationDirectivesString(), query), nil
 	}
 	if optionsOutput.operationName == "" && len(optionsOutput.operationDirectives) == 0 {
 		return "subscription" + query, nil
@@ -109,7 +170,8 @@ func ConstructSubscription(v interface{}, variables map[string]interface{}, opti
 // queryArguments constructs a minified arguments string for variables.
 //
 // E.g., map[string]interface{}{"a": int(123), "b": true} -> "$a:Int!$b:Boolean!".
-func queryArguments(variables map[string]interface{}) string {
+// Var values are written according to their definition.
+func queryArguments(variables map[string]interface{}, options *constructOptionsOutput) (string, error) {
 	// Sort keys in order to produce deterministic output for testing purposes.
 	// TODO: If tests can be made to work with non-deterministic output, then no need to sort.
 	keys := make([]string, 0, len(variables))
@@ -123,21 +185,37 @@ func queryArguments(variables map[string]interface{}) string {
 		io.WriteString(&buf, "$")
 		io.WriteString(&buf, k)
 		io.WriteString(&buf, ":")
-		writeArgumentType(&buf, reflect.TypeOf(variables[k]), true)
+		if v, ok := asVar(variables[k]); ok {
+			if err := writeVarDefinition(&buf, v, options); err != nil {
+				return "", fmt.Errorf("invalid variable $%s: %w", k, err)
+			}
+		} else if err := writeVariableType(&buf, variables[k], options); err != nil {
+			return "", fmt.Errorf("invalid variable $%s: %w", k, err)
+		}
 		// Don't insert a comma here.
 		// Commas in GraphQL are insignificant, and we want minified output.
 		// See https://facebook.github.io/graphql/October2016/#sec-Insignificant-Commas.
 	}
-	return buf.String()
+	return buf.String(), nil
 }
 
 // writeArgumentType writes a minified GraphQL type for t to w.
 // value indicates whether t is a value (required) type or pointer (optional) type.
 // If value is true, then "!" is written at the end of t.
-func writeArgumentType(w io.Writer, t reflect.Type, value bool) {
+// The scalar types of options take precedence over the GraphQLType interface.
+func writeArgumentType(w io.Writer, t reflect.Type, value bool, options *constructOptionsOutput) {
 	if t.Kind() == reflect.Ptr {
 		// Pointer is an optional type, so no "!" at the end of the pointer's underlying type.
-		writeArgumentType(w, t.Elem(), false)
+		writeArgumentType(w, t.Elem(), false, options)
+		return
+	}
+
+	if name, ok := options.scalarName(t); ok {
+		io.WriteString(w, name)
+		if value && !isNullable(t) {
+			// Value is a required type, so add "!" to the end.
+			io.WriteString(w, "!")
+		}
 		return
 	}
 
@@ -145,7 +223,7 @@ func writeArgumentType(w io.Writer, t reflect.Type, value bool) {
 		graphqlType, ok := reflect.Zero(t).Interface().(GraphQLType)
 		if ok {
 			io.WriteString(w, graphqlType.GetGraphQLType())
-			if value {
+			if value && !isNullable(t) {
 				// Value is a required type, so add "!" to the end.
 				io.WriteString(w, "!")
 			}
@@ -157,7 +235,7 @@ func writeArgumentType(w io.Writer, t reflect.Type, value bool) {
 	case reflect.Slice, reflect.Array:
 		// List. E.g., "[Int]".
 		io.WriteString(w, "[")
-		writeArgumentType(w, t.Elem(), true)
+		writeArgumentType(w, t.Elem(), true, options)
 		io.WriteString(w, "]")
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
@@ -184,9 +262,9 @@ func writeArgumentType(w io.Writer, t reflect.Type, value bool) {
 // a minified query string from the provided struct v.
 //
 // E.g., struct{Foo Int, BarBaz *bool} -> "{foo,barBaz}".
-func query(v interface{}) (string, error) {
+func query(v interface{}, options *constructOptionsOutput) (string, error) {
 	var buf bytes.Buffer
-	err := writeQuery(&buf, reflect.TypeOf(v), reflect.ValueOf(v), false)
+	err := writeQuery(&buf, reflect.TypeOf(v), reflect.ValueOf(v), false, options)
 	if err != nil {
 		return "", fmt.Errorf("failed to write query: %w", err)
 	}
@@ -195,10 +273,22 @@ func query(v interface{}) (string, error) {
 
 // writeQuery writes a minified query for t to w.
 // If inline is true, the struct fields of t are inlined into parent struct.
-func writeQuery(w io.Writer, t reflect.Type, v reflect.Value, inline bool) error {
+// Untagged struct fields are named by the naming strategy of options,
+// and fields of the scalar types of options aren't expanded.
+func writeQuery(w io.Writer, t reflect.Type, v reflect.Value, inline bool, options *constructOptionsOutput) error {
+	// Registered scalar types aren't expanded.
+	if _, ok := options.scalarName(t); ok {
+		return nil
+	}
+	if t == selectionType {
+		if !v.IsValid() || v.IsNil() {
+			return fmt.Errorf("selection is nil")
+		}
+		return writeSelection(w, v.Interface().(*Selection), options)
+	}
 	switch t.Kind() {
 	case reflect.Ptr:
-		err := writeQuery(w, t.Elem(), ElemSafe(v), false)
+		err := writeQuery(w, t.Elem(), ElemSafe(v), false, options)
 		if err != nil {
 			return fmt.Errorf("failed to write query for ptr `%v`: %w", t, err)
 		}
@@ -231,14 +321,14 @@ func writeQuery(w io.Writer, t reflect.Type, v reflect.Value, inline bool) error
 				if ok {
 					io.WriteString(w, value)
 				} else {
-					io.WriteString(w, ident.ParseMixedCaps(f.Name).ToLowerCamelCase())
+					io.WriteString(w, options.fieldName(f.Name))
 				}
 			}
 			// Skip writeQuery if the GraphQL type associated with the filed is scalar
 			if isTrue(f.Tag.Get("scalar")) {
 				continue
 			}
-			err := writeQuery(w, f.Type, FieldSafe(v, i), inlineField)
+			err := writeQuery(w, f.Type, FieldSafe(v, i), inlineField, options)
 			if err != nil {
 				return fmt.Errorf("failed to write query for struct field `%v`: %w", f.Name, err)
 			}
@@ -248,7 +338,7 @@ func writeQuery(w io.Writer, t reflect.Type, v reflect.Value, inline bool) error
 		}
 	case reflect.Slice:
 		if t.Elem().Kind() != reflect.Array {
-			err := writeQuery(w, t.Elem(), IndexSafe(v, 0), false)
+			err := writeQuery(w, t.Elem(), IndexSafe(v, 0), false, options)
 			if err != nil {
 				return fmt.Errorf("failed to write query for slice item `%v`: %w", t, err)
 			}
@@ -271,14 +361,43 @@ func writeQuery(w io.Writer, t reflect.Type, v reflect.Value, inline bool) error
 					val.Type(), key.Type(), val.Type())
 			}
 			_, _ = io.WriteString(w, keyString)
-			err := writeQuery(w, val.Type(), val, false)
+			err := writeQuery(w, val.Type(), val, false, options)
 			if err != nil {
 				return fmt.Errorf("failed to write query for pair[1] `%v`: %w", val.Type(), err)
 			}
 		}
 		_, _ = io.WriteString(w, "}")
 	case reflect.Map:
-		return fmt.Errorf("type %v is not supported, use [][2]interface{} instead", t)
+		if t.Key().Kind() != reflect.String {
+			return fmt.Errorf("type %v is not supported, map keys must be strings", t)
+		}
+		elem := t.Elem()
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		if elem.Kind() == reflect.Interface {
+			// map[string]interface{} is a leaf for untyped JSON values. Don't expand it.
+			return nil
+		}
+		if !v.IsValid() || v.Len() == 0 {
+			return fmt.Errorf("map %v has no keys, each key selects a field", t)
+		}
+		// Each key selects a field with the sub-selection of the map values.
+		// Sort keys in order to produce deterministic output.
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		io.WriteString(w, "{")
+		for i, key := range keys {
+			if i != 0 {
+				io.WriteString(w, ",")
+			}
+			io.WriteString(w, key.String())
+			err := writeQuery(w, t.Elem(), v.MapIndex(key), false, options)
+			if err != nil {
+				return fmt.Errorf("failed to write query for map key %q: %w", key.String(), err)
+			}
+		}
+		io.WriteString(w, "}")
 	}
 	return nil
 }
@@ -304,9 +423,19 @@ func FieldSafe(valStruct reflect.Value, i int) reflect.Value {
 	return reflect.ValueOf(nil)
 }
 
+// isNullable reports whether the value type t represents a nullable GraphQL type.
+func isNullable(t reflect.Type) bool {
+	if !t.Implements(graphqlNullableInterface) {
+		return false
+	}
+	nullable, ok := reflect.Zero(t).Interface().(GraphQLNullable)
+	return ok && nullable.IsGraphQLNullable()
+}
+
 var jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
 var idType = reflect.TypeOf(ID(""))
 var graphqlTypeInterface = reflect.TypeOf((*GraphQLType)(nil)).Elem()
+var graphqlNullableInterface = reflect.TypeOf((*GraphQLNullable)(nil)).Elem()
 
 func isTrue(s string) bool {
 	b, _ := strconv.ParseBool(s)
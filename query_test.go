@@ -2,11 +2,16 @@ package graphql
 
 import (
 	"fmt"
+	"math"
 	"net/url"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/zainirfan13/graphql-client/ident"
+	"github.com/zainirfan13/graphql-client/scalars"
 )
 
 type cachedDirective struct {
@@ -340,6 +345,66 @@ func TestConstructQuery(t *testing.T) {
 			}{},
 			want: `{viewer{login,databaseId}}`,
 		},
+		{
+			options: []Option{FieldNaming(SnakeCaseNaming)},
+			inV: struct {
+				Viewer struct {
+					Login      string
+					CreatedAt  time.Time
+					DatabaseID int
+					AvatarURL  string `graphql:"avatarUrl"`
+				}
+			}{},
+			want: `{viewer{login,created_at,database_id,avatarUrl}}`,
+		},
+		{
+			options: []Option{FieldNaming(strings.ToUpper)},
+			inV: struct {
+				Viewer struct {
+					Login string
+				}
+			}{},
+			want: `{VIEWER{LOGIN}}`,
+		},
+		{
+			options: []Option{FieldNaming(SnakeCaseNamingWith(func() *ident.Namer {
+				namer := ident.NewNamer()
+				namer.SplitDigits = true
+				return namer
+			}()))},
+			inV: struct {
+				Viewer struct {
+					AddressLine1 string
+				}
+			}{},
+			want: `{viewer{address_line_1}}`,
+		},
+		{
+			inV: struct {
+				Nodes map[string]*struct {
+					ID   ID
+					Meta map[string]interface{}
+				}
+			}{
+				Nodes: map[string]*struct {
+					ID   ID
+					Meta map[string]interface{}
+				}{
+					"b: node(id: $b)": nil,
+					"a: node(id: $a)": nil,
+				},
+			},
+			want: `{nodes{a: node(id: $a){id,meta},b: node(id: $b){id,meta}}}`,
+		},
+		{
+			inV: map[string]struct {
+				Login string
+			}{
+				"u1: user(login: \"a\")": {},
+				"u0: user(login: \"b\")": {},
+			},
+			want: `{u0: user(login: "b"){login},u1: user(login: "a"){login}}`,
+		},
 	}
 	for _, tc := range tests {
 		got, err := ConstructQuery(tc.inV, tc.inVariables, tc.options...)
@@ -643,6 +708,133 @@ func TestConstructSubscription(t *testing.T) {
 	}
 }
 
+func TestConstructQuery_scalarTypes(t *testing.T) {
+	type money struct {
+		Amount   string
+		Currency string
+	}
+	scalarTypes := ScalarTypes{}.
+		Register(time.Time{}, "DateTime").
+		Register((*money)(nil), "Money")
+
+	var q struct {
+		Orders []struct {
+			Total     money
+			Refunds   []*money
+			CreatedAt time.Time
+		} `graphql:"orders(since: $since, min: $min, max: $max)"`
+	}
+	variables := map[string]interface{}{
+		"since": []time.Time{},
+		"min":   money{},
+		"max":   (*money)(nil),
+	}
+	got, err := ConstructQuery(&q, variables, Scalars(scalarTypes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `query ($max:Money$min:Money!$since:[DateTime!]!){orders(since: $since, min: $min, max: $max){total,refunds,createdAt}}`; got != want {
+		t.Errorf("\ngot:  %q\nwant: %q\n", got, want)
+	}
+
+	// Later options take precedence over earlier ones.
+	got, err = ConstructQuery(&q, variables, Scalars(scalarTypes), Scalars(ScalarTypes{}.Register(money{}, "Price")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `query ($max:Price$min:Price!$since:[DateTime!]!){orders(since: $since, min: $min, max: $max){total,refunds,createdAt}}`; got != want {
+		t.Errorf("\ngot:  %q\nwant: %q\n", got, want)
+	}
+	if got, want := scalarTypes[reflect.TypeOf(money{})], "Money"; got != want {
+		t.Errorf("options modified the registry: got %q, want %q", got, want)
+	}
+}
+
+func TestScalarTypes_Register(t *testing.T) {
+	// A nil registry is allocated.
+	var scalarTypes ScalarTypes
+	scalarTypes = scalarTypes.Register(time.Time{}, "DateTime")
+	if got, want := scalarTypes[reflect.TypeOf(time.Time{})], "DateTime"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Options copy the registry.
+	option := Scalars(scalarTypes)
+	scalarTypes.Register(time.Time{}, "Timestamp")
+	got, err := ConstructQuery(&struct{ Now time.Time }{}, map[string]interface{}{"at": time.Time{}}, option)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `query ($at:DateTime!){now}`; got != want {
+		t.Errorf("\ngot:  %q\nwant: %q\n", got, want)
+	}
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "nil scalar type Void") {
+			t.Errorf("got panic %v, want a nil scalar type panic", r)
+		}
+	}()
+	ScalarTypes{}.Register(nil, "Void")
+}
+
+func TestConstructQuery_mapErrors(t *testing.T) {
+	tests := []struct {
+		inV  interface{}
+		want string
+	}{
+		{
+			inV: struct {
+				Users map[string]struct{ Login string }
+			}{},
+			want: "failed to write query: failed to write query for struct field `Users`: map map[string]struct { Login string } has no keys, each key selects a field",
+		},
+		{
+			inV: struct {
+				Users map[int]struct{ Login string }
+			}{},
+			want: "failed to write query: failed to write query for struct field `Users`: type map[int]struct { Login string } is not supported, map keys must be strings",
+		},
+		{
+			inV: struct {
+				Users map[int]interface{}
+			}{},
+			want: "failed to write query: failed to write query for struct field `Users`: type map[int]interface {} is not supported, map keys must be strings",
+		},
+	}
+	for _, tc := range tests {
+		_, err := ConstructQuery(tc.inV, nil)
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("got error: %v, want: %s", err, tc.want)
+		}
+	}
+}
+
+func TestConstructQuery_varErrors(t *testing.T) {
+	tests := []struct {
+		variables map[string]interface{}
+		want      string
+	}{
+		{
+			variables: map[string]interface{}{"first": Var{}},
+			want:      "invalid variable $first: type can't be derived from a nil value and default value",
+		},
+		{
+			variables: map[string]interface{}{"first": Var{Type: "Float", Default: math.Inf(1)}},
+			want:      "invalid variable $first: invalid default value: unsupported float value +Inf",
+		},
+		{
+			variables: map[string]interface{}{"first": Var{Type: "Input", Default: map[int]string{}}},
+			want:      "invalid variable $first: invalid default value: unsupported map key type int",
+		},
+	}
+	for _, tc := range tests {
+		_, err := ConstructQuery(struct{ Viewer struct{ Login string } }{}, tc.variables)
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("got error: %v, want: %s", err, tc.want)
+		}
+	}
+}
+
 func TestQueryArguments(t *testing.T) {
 	iVal := int(123)
 	i8Val := int8(12)
@@ -751,15 +943,62 @@ func TestQueryArguments(t *testing.T) {
 			},
 			want: `$id:uuid!$id_optional:uuid$ids:[uuid!]!$ids_optional:[uuid]!$my_uuid:my_uuid!$review:user_review!$review_input:user_review_input!`,
 		},
+		{
+			in: map[string]interface{}{
+				"at":       scalars.DateTime{},
+				"at_null":  scalars.NullDateTime{},
+				"data":     scalars.JSON(nil),
+				"ids":      []scalars.UUID{},
+				"ids_null": []scalars.NullUUID{},
+				"price":    scalars.Decimal("1.5"),
+				"url":      &scalars.URL{},
+			},
+			want: `$at:DateTime!$at_null:DateTime$data:JSON!$ids:[UUID!]!$ids_null:[UUID]!$price:Decimal!$url:URL`,
+		},
+		{
+			in: map[string]interface{}{
+				"after":    Var{Type: "String"},
+				"first":    Var{Value: 20, Type: "Int", Default: 10},
+				"last":     Var{Default: 10},
+				"paint":    &Var{Value: map[string]interface{}{}, Type: "PaintInput!", Default: map[string]interface{}{"color": ColorBlue, "name": "sky"}},
+				"state":    Var{Value: IssueStateOpen, Directives: []string{"@deprecated", `@tag(name: "x")`}},
+				"colors":   Var{Value: []Color{}, Default: []Color{ColorRed, ColorBlue}},
+				"since":    Var{Value: time.Time{}, Type: "DateTime", Default: time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)},
+				"weight":   Var{Value: 1.5, Default: (*float64)(nil)},
+				"filter":   Var{Value: UserReview{}, Type: "ReviewFilter", Default: UserReview{Review: "a\"b\n", UserID: "1"}},
+				"required": Var{Value: ID("someID")},
+			},
+			want: `$after:String$colors:[Color!]!=[RED,BLUE]$filter:ReviewFilter={Review:"a\"b\n",UserID:"1"}$first:Int=10$last:Int!=10$paint:PaintInput!={color:BLUE,name:"sky"}$required:ID!$since:DateTime="2022-01-02T03:04:05Z"$state:IssueState!@deprecated@tag(name: "x")$weight:Float=null`,
+		},
+		{
+			in: map[string]interface{}{
+				"paint": Var{Type: "PaintInput", Default: struct {
+					Color Color  `json:"color"`
+					Name  string `graphql:"name"`
+					Hex   string `json:"hex,omitempty"`
+				}{Color: ColorBlue, Name: "sky"}},
+			},
+			want: `$paint:PaintInput={color:BLUE,name:"sky"}`,
+		},
 	}
 	for i, tc := range tests {
-		got := queryArguments(tc.in)
+		got, err := queryArguments(tc.in, &constructOptionsOutput{})
+		if err != nil {
+			t.Errorf("test case %d: %v", i, err)
+		}
 		if got != tc.want {
 			t.Errorf("test case %d:\n got: %q\nwant: %q", i, got, tc.want)
 		}
 	}
 }
 
+func TestQueryArguments_nullDefault(t *testing.T) {
+	_, err := queryArguments(map[string]interface{}{"weight": Var{Type: "Float!", Default: (*float64)(nil)}}, &constructOptionsOutput{})
+	if want := "non-null type Float! can't have a null default value"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}
+
 var val Uuid
 
 type Uuid uuid.UUID
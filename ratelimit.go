@@ -0,0 +1,279 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit configures the rate limit and the concurrency limit of the requests of a client.
+type RateLimit struct {
+	// Rate is the number of requests per second, unlimited if zero.
+	Rate float64
+	// Burst is the number of requests that can be sent at once, above the rate, the rate rounded up if zero.
+	Burst int
+	// MaxInFlight is the maximum number of requests in flight, unlimited if zero.
+	MaxInFlight int
+	// Adaptive slows down the requests according to the budget reported by the server, so that
+	// it lasts until it resets. The budget is read from the X-RateLimit-Remaining and X-RateLimit-Reset
+	// headers (a Unix time), the RateLimit-Remaining and RateLimit-Reset headers (seconds from now),
+	// or the rateLimit extension of responses, with the remaining and resetAt (RFC 3339) fields.
+	// Requests wait for the Retry-After header of 429 and 503 responses too.
+	Adaptive bool
+	// AdaptiveBelow is the remaining budget below which the requests are spread until the reset, 100 if zero.
+	// Requests wait for the reset once the budget is exhausted.
+	AdaptiveBelow int
+}
+
+// WithRateLimit returns a copy of the client that limits the rate and the concurrency of its requests.
+// Requests wait for their turn, until their context is done. Every HTTP request counts once,
+// e.g. a batch of queries or each endpoint tried by WithFailover, but responses served from
+// the HTTP cache don't. Requests that give up waiting, or that can't be signed, don't count.
+func (c *Client) WithRateLimit(limit RateLimit) *Client {
+	if limit.Burst <= 0 {
+		limit.Burst = int(math.Ceil(limit.Rate))
+	}
+	if limit.AdaptiveBelow <= 0 {
+		limit.AdaptiveBelow = 100
+	}
+	l := &rateLimiter{config: limit, tokens: float64(limit.Burst), last: time.Now()}
+	if limit.MaxInFlight > 0 {
+		l.slots = make(chan struct{}, limit.MaxInFlight)
+	}
+	nc := *c
+	nc.rateLimiter = l
+	return &nc
+}
+
+// rateLimiter is a token bucket of requests, and a semaphore of the requests in flight.
+type rateLimiter struct {
+	config RateLimit
+	slots  chan struct{}
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	// notBefore is the time before which no request is sent, and interval the time between
+	// requests, set from the budget reported by the server.
+	notBefore time.Time
+	interval  time.Duration
+	next      time.Time
+}
+
+// do sends req once it's its turn, and updates the budget from its response.
+func (l *rateLimiter) do(send func(*http.Request) (*http.Response, error), req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	r, err := l.wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			l.cancel(r)
+			return nil, ctx.Err()
+		}
+	}
+	resp, err := send(req)
+	if err != nil {
+		if isSigningError(err) {
+			// The request wasn't sent, so its turn is given back.
+			l.cancel(r)
+		}
+		l.release()
+		return nil, err
+	}
+	if l.config.Adaptive {
+		l.update(resp)
+	}
+	if l.slots != nil {
+		// The request is in flight until its body is closed.
+		resp.Body = &releaseBody{ReadCloser: resp.Body, release: l.release}
+	}
+	return resp, nil
+}
+
+func (l *rateLimiter) release() {
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+// reservation is the turn of a request.
+type reservation struct {
+	// token is set if the request took a token of the bucket.
+	token bool
+	// next and prevNext are the times of the next turn after and before the reservation,
+	// if the requests are spread.
+	next, prevNext time.Time
+}
+
+// wait waits for the turn of a request. The turn is given back if ctx is done first.
+func (l *rateLimiter) wait(ctx context.Context) (reservation, error) {
+	delay, r := l.reserve()
+	if delay <= 0 {
+		return r, nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return r, nil
+	case <-ctx.Done():
+		l.cancel(r)
+		return r, ctx.Err()
+	}
+}
+
+// cancel gives back the turn of a request that wasn't sent: its token, and its time
+// if no later turn was taken.
+func (l *rateLimiter) cancel(r reservation) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if r.token {
+		l.tokens++
+	}
+	if !r.next.IsZero() && l.next.Equal(r.next) {
+		l.next = r.prevNext
+	}
+}
+
+// reserve takes the turn of a request, and returns the time to wait for it.
+func (l *rateLimiter) reserve() (time.Duration, reservation) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var r reservation
+	now := time.Now()
+	at := now
+	if l.config.Rate > 0 {
+		l.tokens = math.Min(float64(l.config.Burst), l.tokens+now.Sub(l.last).Seconds()*l.config.Rate)
+		l.last = now
+		l.tokens--
+		r.token = true
+		if l.tokens < 0 {
+			at = now.Add(time.Duration(-l.tokens / l.config.Rate * float64(time.Second)))
+		}
+	}
+	if at.Before(l.notBefore) {
+		at = l.notBefore
+	}
+	if l.interval > 0 {
+		if at.Before(l.next) {
+			at = l.next
+		}
+		r.prevNext = l.next
+		l.next = at.Add(l.interval)
+		r.next = l.next
+	}
+	return at.Sub(now), r
+}
+
+// update updates the budget from the response.
+func (l *rateLimiter) update(resp *http.Response) {
+	now := time.Now()
+	var retryAfter time.Time
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), now)
+	}
+	remaining, reset, ok := rateLimitHeaders(resp.Header, now)
+	if !ok && resp.StatusCode == http.StatusOK {
+		remaining, reset, ok = rateLimitExtension(resp)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if retryAfter.After(l.notBefore) {
+		l.notBefore = retryAfter
+	}
+	if !ok {
+		return
+	}
+	switch {
+	case remaining <= 0:
+		if reset.After(l.notBefore) {
+			l.notBefore = reset
+		}
+		l.interval = 0
+	case remaining < l.config.AdaptiveBelow && reset.After(now):
+		l.interval = reset.Sub(now) / time.Duration(remaining)
+	default:
+		l.interval = 0
+	}
+}
+
+// rateLimitHeaders returns the budget of the rate limit headers.
+func rateLimitHeaders(header http.Header, now time.Time) (int, time.Time, bool) {
+	if remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining")); err == nil {
+		reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+		if err != nil {
+			return 0, time.Time{}, false
+		}
+		return remaining, time.Unix(reset, 0), true
+	}
+	if remaining, err := strconv.Atoi(header.Get("RateLimit-Remaining")); err == nil {
+		seconds, err := strconv.Atoi(header.Get("RateLimit-Reset"))
+		if err != nil {
+			return 0, time.Time{}, false
+		}
+		return remaining, now.Add(time.Duration(seconds) * time.Second), true
+	}
+	return 0, time.Time{}, false
+}
+
+// rateLimitExtension returns the budget of the rateLimit extension of the response.
+func rateLimitExtension(resp *http.Response) (int, time.Time, bool) {
+	body, err := readBody(resp)
+	if err != nil || body == nil || body[0] != '{' {
+		return 0, time.Time{}, false
+	}
+	var r struct {
+		Extensions struct {
+			RateLimit *struct {
+				Remaining *int    `json:"remaining"`
+				ResetAt   *string `json:"resetAt"`
+			} `json:"rateLimit"`
+		} `json:"extensions"`
+	}
+	if json.Unmarshal(body, &r) != nil || r.Extensions.RateLimit == nil ||
+		r.Extensions.RateLimit.Remaining == nil || r.Extensions.RateLimit.ResetAt == nil {
+		return 0, time.Time{}, false
+	}
+	reset, err := time.Parse(time.RFC3339, *r.Extensions.RateLimit.ResetAt)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	return *r.Extensions.RateLimit.Remaining, reset, true
+}
+
+// parseRetryAfter returns the time of a Retry-After header, in seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+// releaseBody calls release once when it's closed.
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,167 @@
+package graphql_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zainirfan13/graphql-client"
+)
+
+func rateLimitQuery(ctx context.Context, client *graphql.Client) error {
+	var q struct {
+		Viewer struct {
+			Login string
+		}
+	}
+	return client.Query(ctx, &q, nil)
+}
+
+func TestClient_WithRateLimit(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithRateLimit(graphql.RateLimit{Rate: 20, Burst: 1})
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := rateLimitQuery(context.Background(), client); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("sent 4 requests in %v, want at least 150ms at 20 requests per second", elapsed)
+	}
+
+	// The request waits for its turn until its context is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_ = rateLimitQuery(context.Background(), client)
+	if err := rateLimitQuery(ctx, client); err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Errorf("got error %v, want the deadline of the context", err)
+	}
+}
+
+func TestClient_WithRateLimit_maxInFlight(t *testing.T) {
+	var inFlight, maxInFlight int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithRateLimit(graphql.RateLimit{MaxInFlight: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := rateLimitQuery(context.Background(), client); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := atomic.LoadInt32(&maxInFlight); got != 2 {
+		t.Errorf("got %d requests in flight at most, want 2", got)
+	}
+}
+
+func TestClient_WithRateLimit_refund(t *testing.T) {
+	var inFlight int32
+	release := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&inFlight, 1) == 1 {
+			<-release
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+	})
+	// The bucket doesn't refill during the test.
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithRateLimit(graphql.RateLimit{Rate: 0.001, Burst: 2, MaxInFlight: 1})
+
+	done := make(chan error)
+	go func() { done <- rateLimitQuery(context.Background(), client) }()
+	waitFor(t, func() bool { return atomic.LoadInt32(&inFlight) == 1 })
+
+	// The request that gives up waiting for a slot gives its token back.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := rateLimitQuery(ctx, client); err == nil {
+		t.Error("got no error while waiting for a slot")
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := rateLimitQuery(ctx, client); err != nil {
+		t.Errorf("got error %v, want the token of the canceled request", err)
+	}
+}
+
+func TestClient_WithRateLimit_adaptive(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		times []time.Time
+		reset time.Time
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		now := time.Now()
+		times = append(times, now)
+		n := len(times)
+		if n == 1 {
+			reset = now.Add(1600 * time.Millisecond)
+		}
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if n == 1 {
+			// The budget is exhausted until the reset.
+			w.Header().Set("RateLimit-Remaining", "0")
+			w.Header().Set("RateLimit-Reset", "1")
+		}
+		mustWrite(w, fmt.Sprintf(`{"data": {"viewer": {"login": "gopher"}}, "extensions": {"rateLimit": {"remaining": 3, "resetAt": %q}}}`,
+			reset.Format(time.RFC3339Nano)))
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithRateLimit(graphql.RateLimit{Adaptive: true})
+
+	for i := 0; i < 4; i++ {
+		if err := rateLimitQuery(context.Background(), client); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The second request waits for the reset of the budget, then the remaining budget of 3
+	// is spread until the next reset, 1.6s after the first request, i.e. every 200ms.
+	mu.Lock()
+	defer mu.Unlock()
+	if elapsed := times[1].Sub(times[0]); elapsed < 900*time.Millisecond {
+		t.Errorf("sent the second request after %v, want it to wait for the reset", elapsed)
+	}
+	if elapsed := times[3].Sub(times[1]); elapsed < 100*time.Millisecond {
+		t.Errorf("sent the last 3 requests in %v, want them spread until the reset", elapsed)
+	}
+}
@@ -0,0 +1,86 @@
+package scalars
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DateLayout is the layout of the Date scalar.
+const DateLayout = "2006-01-02"
+
+// DateTime is a date-time string in RFC 3339 format, e.g. "2006-01-02T15:04:05Z".
+type DateTime struct {
+	time.Time
+}
+
+// NewDateTime creates a DateTime from t.
+func NewDateTime(t time.Time) DateTime {
+	return DateTime{t}
+}
+
+// GetGraphQLType returns the GraphQL type name of DateTime.
+func (DateTime) GetGraphQLType() string { return DateTimeName }
+
+// MarshalJSON implements json.Marshaler.
+func (t DateTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Format(time.RFC3339Nano))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *DateTime) UnmarshalJSON(data []byte) error {
+	if isNull(data) {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid %s: %w", DateTimeName, err)
+	}
+	v, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", DateTimeName, err)
+	}
+	t.Time = v
+	return nil
+}
+
+// Date is a calendar date string in RFC 3339 full-date format, e.g. "2006-01-02".
+// The time of the date is midnight UTC.
+type Date struct {
+	time.Time
+}
+
+// NewDate creates a Date from the year, month and day of t.
+func NewDate(t time.Time) Date {
+	return Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+// GetGraphQLType returns the GraphQL type name of Date.
+func (Date) GetGraphQLType() string { return DateName }
+
+// String returns the date in DateLayout.
+func (d Date) String() string {
+	return d.Format(DateLayout)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Format(DateLayout))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if isNull(data) {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid %s: %w", DateName, err)
+	}
+	v, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", DateName, err)
+	}
+	d.Time = v
+	return nil
+}
@@ -0,0 +1,45 @@
+package scalars
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// JSON is an arbitrary JSON value, kept in its raw encoding.
+// Unlike fields tagged with `scalar:"true"`, it can be used in variables as well.
+type JSON json.RawMessage
+
+// NewJSON creates a JSON scalar from the JSON encoding of v.
+func NewJSON(v interface{}) (JSON, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", JSONName, err)
+	}
+	return JSON(data), nil
+}
+
+// GetGraphQLType returns the GraphQL type name of JSON.
+func (JSON) GetGraphQLType() string { return JSONName }
+
+// Unmarshal decodes the JSON value into v.
+func (j JSON) Unmarshal(v interface{}) error {
+	return json.Unmarshal(j, v)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return null, nil
+	}
+	return json.RawMessage(j).MarshalJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (j *JSON) UnmarshalJSON(data []byte) error {
+	if isNull(data) {
+		*j = nil
+		return nil
+	}
+	*j = append((*j)[:0], data...)
+	return nil
+}
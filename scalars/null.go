@@ -0,0 +1,236 @@
+package scalars
+
+// The Null variants of the scalars. Their variables are declared as nullable,
+// e.g. "$at:DateTime" instead of "$at:DateTime!", and they are marshaled as
+// null unless Valid is true. Unmarshaling null sets Valid to false.
+
+// NullDateTime represents a DateTime that may be null.
+type NullDateTime struct {
+	DateTime DateTime
+	Valid    bool // Valid is true if DateTime is not null.
+}
+
+// GetGraphQLType returns the GraphQL type name of DateTime.
+func (NullDateTime) GetGraphQLType() string { return DateTimeName }
+
+// IsGraphQLNullable reports that NullDateTime variables are nullable.
+func (NullDateTime) IsGraphQLNullable() bool { return true }
+
+// MarshalJSON implements json.Marshaler.
+func (n NullDateTime) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return null, nil
+	}
+	return n.DateTime.MarshalJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *NullDateTime) UnmarshalJSON(data []byte) error {
+	if isNull(data) {
+		*n = NullDateTime{}
+		return nil
+	}
+	if err := n.DateTime.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// NullDate represents a Date that may be null.
+type NullDate struct {
+	Date  Date
+	Valid bool // Valid is true if Date is not null.
+}
+
+// GetGraphQLType returns the GraphQL type name of Date.
+func (NullDate) GetGraphQLType() string { return DateName }
+
+// IsGraphQLNullable reports that NullDate variables are nullable.
+func (NullDate) IsGraphQLNullable() bool { return true }
+
+// MarshalJSON implements json.Marshaler.
+func (n NullDate) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return null, nil
+	}
+	return n.Date.MarshalJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *NullDate) UnmarshalJSON(data []byte) error {
+	if isNull(data) {
+		*n = NullDate{}
+		return nil
+	}
+	if err := n.Date.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// NullJSON represents a JSON that may be null.
+type NullJSON struct {
+	JSON  JSON
+	Valid bool // Valid is true if JSON is not null.
+}
+
+// GetGraphQLType returns the GraphQL type name of JSON.
+func (NullJSON) GetGraphQLType() string { return JSONName }
+
+// IsGraphQLNullable reports that NullJSON variables are nullable.
+func (NullJSON) IsGraphQLNullable() bool { return true }
+
+// MarshalJSON implements json.Marshaler.
+func (n NullJSON) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return null, nil
+	}
+	return n.JSON.MarshalJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *NullJSON) UnmarshalJSON(data []byte) error {
+	if isNull(data) {
+		*n = NullJSON{}
+		return nil
+	}
+	if err := n.JSON.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// NullBigInt represents a BigInt that may be null.
+type NullBigInt struct {
+	BigInt BigInt
+	Valid  bool // Valid is true if BigInt is not null.
+}
+
+// GetGraphQLType returns the GraphQL type name of BigInt.
+func (NullBigInt) GetGraphQLType() string { return BigIntName }
+
+// IsGraphQLNullable reports that NullBigInt variables are nullable.
+func (NullBigInt) IsGraphQLNullable() bool { return true }
+
+// MarshalJSON implements json.Marshaler.
+func (n NullBigInt) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return null, nil
+	}
+	return n.BigInt.MarshalJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *NullBigInt) UnmarshalJSON(data []byte) error {
+	if isNull(data) {
+		*n = NullBigInt{}
+		return nil
+	}
+	if err := n.BigInt.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// NullDecimal represents a Decimal that may be null.
+type NullDecimal struct {
+	Decimal Decimal
+	Valid   bool // Valid is true if Decimal is not null.
+}
+
+// GetGraphQLType returns the GraphQL type name of Decimal.
+func (NullDecimal) GetGraphQLType() string { return DecimalName }
+
+// IsGraphQLNullable reports that NullDecimal variables are nullable.
+func (NullDecimal) IsGraphQLNullable() bool { return true }
+
+// MarshalJSON implements json.Marshaler.
+func (n NullDecimal) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return null, nil
+	}
+	return n.Decimal.MarshalJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *NullDecimal) UnmarshalJSON(data []byte) error {
+	if isNull(data) {
+		*n = NullDecimal{}
+		return nil
+	}
+	if err := n.Decimal.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// NullUUID represents a UUID that may be null.
+type NullUUID struct {
+	UUID  UUID
+	Valid bool // Valid is true if UUID is not null.
+}
+
+// GetGraphQLType returns the GraphQL type name of UUID.
+func (NullUUID) GetGraphQLType() string { return UUIDName }
+
+// IsGraphQLNullable reports that NullUUID variables are nullable.
+func (NullUUID) IsGraphQLNullable() bool { return true }
+
+// MarshalJSON implements json.Marshaler.
+func (n NullUUID) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return null, nil
+	}
+	return n.UUID.MarshalJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *NullUUID) UnmarshalJSON(data []byte) error {
+	if isNull(data) {
+		*n = NullUUID{}
+		return nil
+	}
+	if err := n.UUID.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// NullURL represents a URL that may be null.
+type NullURL struct {
+	URL   URL
+	Valid bool // Valid is true if URL is not null.
+}
+
+// GetGraphQLType returns the GraphQL type name of URL.
+func (NullURL) GetGraphQLType() string { return URLName }
+
+// IsGraphQLNullable reports that NullURL variables are nullable.
+func (NullURL) IsGraphQLNullable() bool { return true }
+
+// MarshalJSON implements json.Marshaler.
+func (n NullURL) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return null, nil
+	}
+	return n.URL.MarshalJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *NullURL) UnmarshalJSON(data []byte) error {
+	if isNull(data) {
+		*n = NullURL{}
+		return nil
+	}
+	if err := n.URL.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
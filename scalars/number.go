@@ -0,0 +1,194 @@
+package scalars
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// BigInt is an arbitrary-precision integer. It is marshaled as a JSON string,
+// so that JavaScript servers don't lose precision, and unmarshaled from either
+// a JSON string or a JSON number. The zero value is 0.
+//
+// A BigInt is immutable, so it can be copied safely.
+type BigInt struct {
+	i *big.Int
+}
+
+// NewBigInt creates a BigInt from x.
+func NewBigInt(x int64) BigInt {
+	return BigInt{big.NewInt(x)}
+}
+
+// NewBigIntFromInt creates a BigInt from a copy of x.
+func NewBigIntFromInt(x *big.Int) BigInt {
+	return BigInt{new(big.Int).Set(x)}
+}
+
+// ParseBigInt parses a BigInt from its base 10 representation.
+func ParseBigInt(s string) (BigInt, error) {
+	i, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return BigInt{}, fmt.Errorf("invalid %s: %q", BigIntName, s)
+	}
+	return BigInt{i}, nil
+}
+
+// GetGraphQLType returns the GraphQL type name of BigInt.
+func (BigInt) GetGraphQLType() string { return BigIntName }
+
+// Int returns a copy of the value of b.
+func (b BigInt) Int() *big.Int {
+	if b.i == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(b.i)
+}
+
+// String returns the base 10 representation of b.
+func (b BigInt) String() string {
+	if b.i == nil {
+		return "0"
+	}
+	return b.i.String()
+}
+
+// MarshalJSON implements json.Marshaler.
+func (b BigInt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (b *BigInt) UnmarshalJSON(data []byte) error {
+	if isNull(data) {
+		return nil
+	}
+	s, err := unquote(data)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %s", BigIntName, data)
+	}
+	v, err := ParseBigInt(s)
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+// Decimal is an arbitrary-precision decimal number, kept in its exact textual
+// representation, e.g. "12.50". It is marshaled as a JSON number and unmarshaled
+// from either a JSON string or a JSON number.
+type Decimal string
+
+// ParseDecimal validates s and converts it to Decimal.
+// Only JSON numbers are valid, e.g. "-1.5e3" but not "0x10", "1/3" or ".5".
+func ParseDecimal(s string) (Decimal, error) {
+	if !isJSONNumber(s) {
+		return "", fmt.Errorf("invalid %s: %q", DecimalName, s)
+	}
+	return Decimal(s), nil
+}
+
+// GetGraphQLType returns the GraphQL type name of Decimal.
+func (Decimal) GetGraphQLType() string { return DecimalName }
+
+// Rat returns the exact value of the decimal.
+// The zero value of Decimal is 0.
+func (d Decimal) Rat() *big.Rat {
+	r, ok := new(big.Rat).SetString(string(d))
+	if !ok {
+		return new(big.Rat)
+	}
+	return r
+}
+
+// Float64 returns the nearest float64 value of the decimal.
+func (d Decimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	if d == "" {
+		return []byte("0"), nil
+	}
+	if _, err := ParseDecimal(string(d)); err != nil {
+		return nil, err
+	}
+	return []byte(d), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if isNull(data) {
+		return nil
+	}
+	s, err := unquote(data)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %s", DecimalName, data)
+	}
+	v, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// unquote returns the content of the JSON string data, or data itself if it isn't a string.
+func unquote(data []byte) (string, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '"' {
+		return string(data), nil
+	}
+	var s string
+	err := json.Unmarshal(data, &s)
+	return s, err
+}
+
+// isJSONNumber reports whether s matches the grammar of JSON numbers:
+// an optional minus sign, an integer part without leading zeros,
+// an optional fraction and an optional exponent.
+func isJSONNumber(s string) bool {
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	switch {
+	case i < len(s) && s[i] == '0':
+		i++
+	case i < len(s) && '1' <= s[i] && s[i] <= '9':
+		i = skipDigits(s, i)
+	default:
+		return false
+	}
+	if i < len(s) && s[i] == '.' {
+		j := skipDigits(s, i+1)
+		if j == i+1 {
+			return false
+		}
+		i = j
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		j := skipDigits(s, i)
+		if j == i {
+			return false
+		}
+		i = j
+	}
+	return i == len(s)
+}
+
+// skipDigits returns the index of the first non-digit byte of s from i.
+func skipDigits(s string, i int) int {
+	for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,36 @@
+// Package scalars provides Go types for custom GraphQL scalars that are
+// common across GraphQL servers: DateTime, Date, JSON, BigInt, Decimal, UUID and URL.
+//
+// Each type implements the GraphQLType interface of the graphql package,
+// so variables are declared with the right type name, and is (un)marshaled
+// to and from its JSON representation. Servers don't agree on the names of
+// these scalars, e.g. DateTime is called Timestamp or timestamptz on some
+// servers. The names can be changed with the package variables below.
+//
+// The Null variants, e.g. NullDateTime, represent nullable values. They are
+// declared as nullable variables and marshaled as null if they aren't valid,
+// like the types of the database/sql package.
+package scalars
+
+import (
+	"bytes"
+)
+
+// GraphQL type names of the scalars. They are read whenever a query is built,
+// so they should be set once at initialization.
+var (
+	DateTimeName = "DateTime"
+	DateName     = "Date"
+	JSONName     = "JSON"
+	BigIntName   = "BigInt"
+	DecimalName  = "Decimal"
+	UUIDName     = "UUID"
+	URLName      = "URL"
+)
+
+var null = []byte("null")
+
+// isNull reports whether data is the JSON null literal.
+func isNull(data []byte) bool {
+	return bytes.Equal(bytes.TrimSpace(data), null)
+}
@@ -0,0 +1,223 @@
+package scalars_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/zainirfan13/graphql-client/scalars"
+)
+
+func TestDateTime(t *testing.T) {
+	want := scalars.NewDateTime(time.Date(2022, 3, 4, 5, 6, 7, 800, time.UTC))
+	b, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `"2022-03-04T05:06:07.0000008Z"`; got != want {
+		t.Errorf("got: %s, want: %s", got, want)
+	}
+	var got scalars.DateTime
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(want.Time) {
+		t.Errorf("got: %v, want: %v", got, want)
+	}
+	if err := json.Unmarshal([]byte(`"2022-03-04"`), &got); err == nil {
+		t.Error("got error: nil, want: non-nil")
+	}
+}
+
+func TestDate(t *testing.T) {
+	d := scalars.NewDate(time.Date(2022, 3, 4, 23, 0, 0, 0, time.FixedZone("", 3600)))
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `"2022-03-04"`; got != want {
+		t.Errorf("got: %s, want: %s", got, want)
+	}
+	var got scalars.Date
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != d {
+		t.Errorf("got: %v, want: %v", got, d)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	j, err := scalars.NewJSON(map[string]interface{}{"a": []int{1, 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(struct{ Data scalars.JSON }{j})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"Data":{"a":[1,2]}}`; got != want {
+		t.Errorf("got: %s, want: %s", got, want)
+	}
+	var got struct{ Data scalars.JSON }
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	var v struct{ A []int }
+	if err := got.Data.Unmarshal(&v); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.A) != 2 || v.A[1] != 2 {
+		t.Errorf("got: %v, want: [1 2]", v.A)
+	}
+	b, _ = json.Marshal(scalars.JSON(nil))
+	if got, want := string(b), `null`; got != want {
+		t.Errorf("got: %s, want: %s", got, want)
+	}
+}
+
+func TestBigInt(t *testing.T) {
+	want, err := scalars.ParseBigInt("123456789012345678901234567890")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `"123456789012345678901234567890"`; got != want {
+		t.Errorf("got: %s, want: %s", got, want)
+	}
+	for _, in := range []string{`"123456789012345678901234567890"`, `123456789012345678901234567890`} {
+		var got scalars.BigInt
+		if err := json.Unmarshal([]byte(in), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Int().Cmp(want.Int()) != 0 {
+			t.Errorf("got: %v, want: %v", got, want)
+		}
+	}
+	for _, in := range []string{`"1.5"`, `"123`, `123"`, `"0x10"`} {
+		var got scalars.BigInt
+		if err := got.UnmarshalJSON([]byte(in)); err == nil {
+			t.Errorf("%s: got error: nil, want: non-nil", in)
+		}
+	}
+
+	// Copies don't share their value.
+	a := scalars.NewBigInt(1)
+	c := a
+	c.Int().SetInt64(2)
+	if err := json.Unmarshal([]byte(`3`), &c); err != nil {
+		t.Fatal(err)
+	}
+	if a.String() != "1" || c.String() != "3" {
+		t.Errorf("got: %v and %v, want: 1 and 3", a, c)
+	}
+	if got := (scalars.BigInt{}).String(); got != "0" {
+		t.Errorf("got: %s, want: 0", got)
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	b, err := json.Marshal(scalars.Decimal("12.50"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `12.50`; got != want {
+		t.Errorf("got: %s, want: %s", got, want)
+	}
+	for _, in := range []string{`"0.10"`, `0.10`} {
+		var got scalars.Decimal
+		if err := json.Unmarshal([]byte(in), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got != "0.10" {
+			t.Errorf("got: %s, want: 0.10", got)
+		}
+		if got.Rat().Cmp(big.NewRat(1, 10)) != 0 {
+			t.Errorf("got: %v, want: 1/10", got.Rat())
+		}
+	}
+	for _, in := range []string{"1/3", "0x10", ".5", "1.", "01", "+1", "1e", "NaN"} {
+		if _, err := scalars.ParseDecimal(in); err == nil {
+			t.Errorf("%s: got error: nil, want: non-nil", in)
+		}
+	}
+	for _, in := range []string{"0", "-0.5", "1e10", "1.5E-3", "12.50"} {
+		if _, err := scalars.ParseDecimal(in); err != nil {
+			t.Errorf("%s: got error: %v", in, err)
+		}
+	}
+	var d scalars.Decimal
+	if err := d.UnmarshalJSON([]byte(`"1.5`)); err == nil {
+		t.Error("got error: nil, want: non-nil")
+	}
+	if _, err := json.Marshal(scalars.Decimal("abc")); err == nil {
+		t.Error("got error: nil, want: non-nil")
+	}
+}
+
+func TestUUID(t *testing.T) {
+	in := `"f47ac10b-58cc-0372-8567-0e02b2c3d479"`
+	var got scalars.UUID
+	if err := json.Unmarshal([]byte(in), &got); err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != in {
+		t.Errorf("got: %s, want: %s", b, in)
+	}
+	if err := json.Unmarshal([]byte(`"invalid"`), &got); err == nil {
+		t.Error("got error: nil, want: non-nil")
+	}
+}
+
+func TestURL(t *testing.T) {
+	in := `"https://example.com/graphql?a=1"`
+	var got scalars.URL
+	if err := json.Unmarshal([]byte(in), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Host != "example.com" {
+		t.Errorf("got host: %s, want: example.com", got.Host)
+	}
+	b, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != in {
+		t.Errorf("got: %s, want: %s", b, in)
+	}
+}
+
+func TestNullVariants(t *testing.T) {
+	var v struct {
+		At   scalars.NullDateTime
+		Date scalars.NullDate
+		ID   scalars.NullUUID
+	}
+	if err := json.Unmarshal([]byte(`{"At": "2022-03-04T05:06:07Z", "Date": null}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if !v.At.Valid || v.At.DateTime.Year() != 2022 {
+		t.Errorf("got: %+v, want: valid 2022-03-04T05:06:07Z", v.At)
+	}
+	if v.Date.Valid || v.ID.Valid {
+		t.Errorf("got valid null values: %+v, %+v", v.Date, v.ID)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"At":"2022-03-04T05:06:07Z","Date":null,"ID":null}`; got != want {
+		t.Errorf("got: %s, want: %s", got, want)
+	}
+	if !(scalars.NullBigInt{}).IsGraphQLNullable() {
+		t.Error("NullBigInt should be nullable")
+	}
+}
@@ -0,0 +1,51 @@
+package scalars
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// URL is an RFC 3986 URL string, e.g. "https://example.com/graphql".
+type URL struct {
+	url.URL
+}
+
+// NewURL creates a URL from u.
+func NewURL(u url.URL) URL {
+	return URL{u}
+}
+
+// ParseURL parses a URL from its string form.
+func ParseURL(s string) (URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return URL{}, fmt.Errorf("invalid %s: %w", URLName, err)
+	}
+	return URL{*u}, nil
+}
+
+// GetGraphQLType returns the GraphQL type name of URL.
+func (URL) GetGraphQLType() string { return URLName }
+
+// MarshalJSON implements json.Marshaler.
+func (u URL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (u *URL) UnmarshalJSON(data []byte) error {
+	if isNull(data) {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid %s: %w", URLName, err)
+	}
+	v, err := ParseURL(s)
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
@@ -0,0 +1,53 @@
+package scalars
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// UUID is a universally unique identifier in its canonical string form,
+// e.g. "f47ac10b-58cc-0372-8567-0e02b2c3d479".
+type UUID struct {
+	uuid.UUID
+}
+
+// NewUUID creates a UUID from u.
+func NewUUID(u uuid.UUID) UUID {
+	return UUID{u}
+}
+
+// ParseUUID parses a UUID from its string form.
+func ParseUUID(s string) (UUID, error) {
+	u, err := uuid.Parse(s)
+	if err != nil {
+		return UUID{}, fmt.Errorf("invalid %s: %w", UUIDName, err)
+	}
+	return UUID{u}, nil
+}
+
+// GetGraphQLType returns the GraphQL type name of UUID.
+func (UUID) GetGraphQLType() string { return UUIDName }
+
+// MarshalJSON implements json.Marshaler.
+func (u UUID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (u *UUID) UnmarshalJSON(data []byte) error {
+	if isNull(data) {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid %s: %w", UUIDName, err)
+	}
+	v, err := ParseUUID(s)
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
@@ -0,0 +1,235 @@
+package graphql
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Selection is a selection set built at runtime, for queries that can't be
+// declared with structs, such as dynamic queries and multiple mutations.
+// It replaces the [][2]interface{} ordered map with a builder that
+// can't produce malformed pairs.
+//
+// Each field has a destination where its value is decoded: a pointer to any
+// type that can be used in query structs, a nested *Selection, or nil for
+// an untyped value that is returned by Get. Untyped values are decoded as
+// by encoding/json into an interface{}, so an object-valued scalar such as
+// JSON is decoded as a map[string]interface{}.
+//
+//	var login string
+//	var repos struct{ TotalCount int }
+//	q := graphql.NewSelection().
+//		Field("user", graphql.NewSelection().
+//			Field("login", &login).
+//			Field("repositories", &repos).Args(map[string]interface{}{"first": graphql.Variable("first")})).
+//		Args(map[string]interface{}{"login": "gopher"})
+//	// {user(login:"gopher"){login,repositories(first:$first){totalCount}}}
+//
+// Alias, Args and Directive apply to the last field or fragment that was added.
+//
+// Like the destinations of query structs, the values of a Selection are
+// overwritten by each request, so it shouldn't be used by concurrent requests.
+type Selection struct {
+	fields []selectionField
+	// pairs holds the rendered selection of each field and its destination,
+	// which is a new *interface{} for untyped fields. The decoder stores
+	// values through the destinations, and never modifies pairs.
+	pairs [][2]interface{}
+	err   error
+}
+
+type selectionField struct {
+	name       string
+	alias      string
+	args       map[string]interface{}
+	directives []string
+	fragment   bool
+	untyped    bool
+}
+
+// Variable is an argument value that refers to the variable of the operation with that name.
+//
+// E.g., Variable("first") is written as $first.
+type Variable string
+
+// NewSelection creates an empty selection set.
+func NewSelection() *Selection {
+	return &Selection{}
+}
+
+// Field adds a field with the destination v.
+func (s *Selection) Field(name string, v interface{}) *Selection {
+	return s.add(selectionField{name: name}, v)
+}
+
+// Fragment adds an inline fragment on typeCondition, with the destination v.
+// v is usually a pointer to a struct or a *Selection.
+func (s *Selection) Fragment(typeCondition string, v interface{}) *Selection {
+	return s.add(selectionField{name: typeCondition, fragment: true}, v)
+}
+
+// Alias sets the alias of the last field.
+func (s *Selection) Alias(alias string) *Selection {
+	return s.modify("Alias", func(f *selectionField) {
+		if f.fragment {
+			s.setErr(fmt.Errorf("fragment on %s can't have an alias", f.name))
+			return
+		}
+		f.alias = alias
+	})
+}
+
+// Args sets arguments of the last field. Values are written as GraphQL literals,
+// enum values without quotes. Use Variable to refer to variables.
+func (s *Selection) Args(args map[string]interface{}) *Selection {
+	return s.modify("Args", func(f *selectionField) {
+		if f.fragment {
+			s.setErr(fmt.Errorf("fragment on %s can't have arguments", f.name))
+			return
+		}
+		if f.args == nil {
+			f.args = make(map[string]interface{}, len(args))
+		}
+		for name, value := range args {
+			f.args[name] = value
+		}
+	})
+}
+
+// Directive adds a directive to the last field or fragment, e.g. "@include(if: $withEmail)".
+func (s *Selection) Directive(directive string) *Selection {
+	return s.modify("Directive", func(f *selectionField) {
+		f.directives = append(f.directives, directive)
+	})
+}
+
+// Get returns the destination of the field with the response name,
+// i.e. its alias or its name, or nil if there is no such field.
+// After decoding, it holds the value of untyped fields.
+func (s *Selection) Get(responseName string) interface{} {
+	for i, f := range s.fields {
+		if !f.fragment && f.responseName() == responseName {
+			if f.untyped {
+				return *s.pairs[i][1].(*interface{})
+			}
+			return s.pairs[i][1]
+		}
+	}
+	return nil
+}
+
+// Pairs returns the selection set as an ordered map,
+// the format used before Selection was introduced.
+func (s *Selection) Pairs() [][2]interface{} {
+	return s.pairs
+}
+
+func (s *Selection) add(f selectionField, v interface{}) *Selection {
+	if v == nil {
+		f.untyped = true
+		v = new(interface{})
+	}
+	s.fields = append(s.fields, f)
+	s.pairs = append(s.pairs, [2]interface{}{nil, v})
+	s.render(len(s.fields) - 1)
+	return s
+}
+
+func (s *Selection) modify(method string, fn func(f *selectionField)) *Selection {
+	if len(s.fields) == 0 {
+		s.setErr(fmt.Errorf("%s called before adding a field", method))
+		return s
+	}
+	i := len(s.fields) - 1
+	fn(&s.fields[i])
+	s.render(i)
+	return s
+}
+
+// setErr records the first error, which is returned when the query is built.
+func (s *Selection) setErr(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// render updates the selection of the field at index i.
+func (s *Selection) render(i int) {
+	f := s.fields[i]
+	var buf bytes.Buffer
+	if f.fragment {
+		buf.WriteString("... on ")
+		buf.WriteString(f.name)
+	} else {
+		if f.alias != "" {
+			buf.WriteString(f.alias)
+			buf.WriteString(":")
+		}
+		buf.WriteString(f.name)
+	}
+	if len(f.args) > 0 {
+		names := make([]string, 0, len(f.args))
+		for name := range f.args {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		buf.WriteString("(")
+		for j, name := range names {
+			if j != 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString(name)
+			buf.WriteString(":")
+			if err := writeLiteral(&buf, reflect.ValueOf(f.args[name])); err != nil {
+				s.setErr(fmt.Errorf("invalid argument %s of field %s: %w", name, f.name, err))
+			}
+		}
+		buf.WriteString(")")
+	}
+	for _, directive := range f.directives {
+		if !strings.HasPrefix(directive, "@") {
+			buf.WriteString("@")
+		}
+		buf.WriteString(directive)
+	}
+	s.pairs[i][0] = buf.String()
+}
+
+func (f selectionField) responseName() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+// writeSelection writes the minified selection set of s to w.
+func writeSelection(w io.Writer, s *Selection, options *constructOptionsOutput) error {
+	if s.err != nil {
+		return s.err
+	}
+	if len(s.pairs) == 0 {
+		return fmt.Errorf("selection has no fields")
+	}
+	io.WriteString(w, "{")
+	for i, pair := range s.pairs {
+		if i != 0 {
+			io.WriteString(w, ",")
+		}
+		io.WriteString(w, pair[0].(string))
+		if s.fields[i].untyped {
+			continue
+		}
+		val := reflect.ValueOf(pair[1])
+		if err := writeQuery(w, val.Type(), val, false, options); err != nil {
+			return fmt.Errorf("failed to write query for selection `%s`: %w", pair[0], err)
+		}
+	}
+	io.WriteString(w, "}")
+	return nil
+}
+
+var selectionType = reflect.TypeOf(&Selection{})
@@ -0,0 +1,185 @@
+package graphql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zainirfan13/graphql-client/internal/jsonutil"
+)
+
+func TestSelection(t *testing.T) {
+	type repository struct {
+		Name       string
+		Stargazers struct {
+			TotalCount int
+		}
+	}
+	var (
+		login  string
+		repos  []repository
+		bot    struct{ Name string }
+		userID ID
+	)
+	user := NewSelection().
+		Field("id", &userID).
+		Field("login", &login).
+		Field("repositories", &repos).Alias("repos").Args(map[string]interface{}{
+		"first":   Variable("first"),
+		"orderBy": map[string]interface{}{"field": Color("RED"), "direction": "DESC"},
+	}).
+		Field("email", nil).Directive("@include(if: $withEmail)").
+		Fragment("Bot", &bot)
+	q := NewSelection().
+		Field("user", user).Args(map[string]interface{}{"login": "gopher"}).
+		Field("viewerCanAdminister", nil)
+
+	got, err := ConstructQuery(q, map[string]interface{}{"first": 10, "withEmail": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `query ($first:Int!$withEmail:Boolean!){user(login:"gopher"){id,login,repos:repositories(first:$first,orderBy:{direction:"DESC",field:RED}){name,stargazers{totalCount}},email@include(if: $withEmail),... on Bot{name}},viewerCanAdminister}`
+	if got != want {
+		t.Errorf("\n got: %q\nwant: %q", got, want)
+	}
+
+	err = jsonutil.UnmarshalGraphQL([]byte(`{
+		"user": {
+			"id": "1",
+			"login": "gopher",
+			"repos": [{"name": "graphql", "stargazers": {"totalCount": 5}}],
+			"email": "gopher@example.com",
+			"name": "bot"
+		},
+		"viewerCanAdminister": false
+	}`), q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if userID != "1" {
+		t.Errorf("got userID %q, want %q", userID, "1")
+	}
+	if login != "gopher" {
+		t.Errorf("got login %q, want %q", login, "gopher")
+	}
+	wantRepos := []repository{{Name: "graphql"}}
+	wantRepos[0].Stargazers.TotalCount = 5
+	if !reflect.DeepEqual(repos, wantRepos) {
+		t.Errorf("got repos %v, want %v", repos, wantRepos)
+	}
+	if bot.Name != "bot" {
+		t.Errorf("got bot.Name %q, want %q", bot.Name, "bot")
+	}
+	if got := user.Get("email"); got != "gopher@example.com" {
+		t.Errorf("got email %v, want %q", got, "gopher@example.com")
+	}
+	if got := q.Get("viewerCanAdminister"); got != false {
+		t.Errorf("got viewerCanAdminister %v, want false", got)
+	}
+	if got := user.Get("repos"); got != &repos {
+		t.Errorf("got repos destination %v, want %p", got, &repos)
+	}
+	if got := user.Get("repositories"); got != nil {
+		t.Errorf("got %v for the name of an aliased field, want nil", got)
+	}
+}
+
+func TestSelection_mutations(t *testing.T) {
+	var user1, user2 struct{ Login string }
+	m := NewSelection().
+		Field("createUser", &user1).Alias("u1").Args(map[string]interface{}{"login": Variable("login1")}).
+		Field("createUser", &user2).Alias("u2").Args(map[string]interface{}{"login": Variable("login2")})
+
+	got, err := ConstructMutation(m, map[string]interface{}{"login1": "grihabor", "login2": "diman"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `mutation ($login1:String!$login2:String!){u1:createUser(login:$login1){login},u2:createUser(login:$login2){login}}`; got != want {
+		t.Errorf("\n got: %q\nwant: %q", got, want)
+	}
+
+	err = jsonutil.UnmarshalGraphQL([]byte(`{"u1": {"login": "grihabor"}, "u2": {"login": "diman"}}`), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user1.Login != "grihabor" || user2.Login != "diman" {
+		t.Errorf("got logins %q and %q", user1.Login, user2.Login)
+	}
+}
+
+func TestSelection_untyped(t *testing.T) {
+	q := NewSelection().
+		Field("login", nil).
+		Field("metadata", nil).
+		Field("tags", nil)
+
+	got, err := ConstructQuery(q, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{login,metadata,tags}`; got != want {
+		t.Errorf("\n got: %q\nwant: %q", got, want)
+	}
+
+	err = jsonutil.UnmarshalGraphQL([]byte(`{"login": "gopher", "metadata": {"theme": "dark", "size": 2}, "tags": ["a", "b"]}`), q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := q.Get("metadata"), map[string]interface{}{"theme": "dark", "size": float64(2)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got metadata %v, want %v", got, want)
+	}
+	if got, want := q.Get("tags"), []interface{}{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got tags %v, want %v", got, want)
+	}
+
+	// The selection can be reused, and values may change type between requests.
+	err = jsonutil.UnmarshalGraphQL([]byte(`{"login": "octocat", "metadata": null, "tags": "c"}`), q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ConstructQuery(q, nil); err != nil || got != `{login,metadata,tags}` {
+		t.Errorf("got query %q and error %v after decoding", got, err)
+	}
+	if got := q.Get("login"); got != "octocat" {
+		t.Errorf("got login %v, want %q", got, "octocat")
+	}
+	if got := q.Get("metadata"); got != nil {
+		t.Errorf("got metadata %v, want nil", got)
+	}
+	if got := q.Get("tags"); got != "c" {
+		t.Errorf("got tags %v, want %q", got, "c")
+	}
+}
+
+func TestSelection_errors(t *testing.T) {
+	tests := []struct {
+		in   *Selection
+		want string
+	}{
+		{
+			in:   NewSelection(),
+			want: "failed to write query: selection has no fields",
+		},
+		{
+			in:   NewSelection().Alias("a"),
+			want: "failed to write query: Alias called before adding a field",
+		},
+		{
+			in:   NewSelection().Fragment("User", nil).Args(map[string]interface{}{"id": 1}),
+			want: "failed to write query: fragment on User can't have arguments",
+		},
+		{
+			in:   NewSelection().Field("user", nil).Args(map[string]interface{}{"id": map[int]int{}}),
+			want: "failed to write query: invalid argument id of field user: unsupported map key type int",
+		},
+		{
+			in:   NewSelection().Field("user", NewSelection()),
+			want: "failed to write query: failed to write query for selection `user`: selection has no fields",
+		},
+	}
+	for _, tc := range tests {
+		_, err := ConstructQuery(tc.in, nil)
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("got error: %v, want: %s", err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Shadow configures the shadow backend of a client.
+type Shadow struct {
+	// URL is the GraphQL server URL of the shadow backend.
+	URL string
+	// HTTPClient sends the shadow requests, the HTTP client of the client if nil.
+	HTTPClient *http.Client
+	// Timeout is the timeout of the shadow requests, 30s if zero.
+	Timeout time.Duration
+	// MaxInFlight is the maximum number of shadow requests in flight, 10 if zero.
+	// Queries aren't shadowed while it's reached.
+	MaxInFlight int
+	// OnDiff is called with the differences between the responses of the primary and the shadow backend
+	// to a query, if any.
+	OnDiff func(query string, variables map[string]interface{}, diffs []ShadowDiff)
+}
+
+// ShadowDiff is a difference between the responses of the primary and the shadow backend.
+type ShadowDiff struct {
+	// Path is the path of the value in data, e.g. viewer.repositories[0].name,
+	// or "errors" for the messages of the errors.
+	Path string
+	// Primary and Shadow are the JSON values of the responses, nil if missing.
+	// For errors, they are the sorted messages of the errors.
+	Primary json.RawMessage
+	Shadow  json.RawMessage
+}
+
+func (d ShadowDiff) String() string {
+	value := func(v json.RawMessage) string {
+		if v == nil {
+			return "<missing>"
+		}
+		return string(v)
+	}
+	return fmt.Sprintf("%s: %s != %s", d.Path, value(d.Primary), value(d.Shadow))
+}
+
+// WithShadow returns a copy of the client that also sends each query to a shadow backend, e.g. while migrating
+// to another server. The result of the primary backend is returned, and the data and errors of both responses
+// are compared asynchronously, reporting the differences to the OnDiff callback. Mutations aren't shadowed.
+//
+// The shadow requests are sent with the request modifier, the token provider and the request signer of the client,
+// without its other features, e.g. caching or batching. OnDiff is called with the variables
+// encoded as JSON, as they were sent.
+func (c *Client) WithShadow(shadow Shadow) *Client {
+	if shadow.Timeout <= 0 {
+		shadow.Timeout = 30 * time.Second
+	}
+	if shadow.MaxInFlight <= 0 {
+		shadow.MaxInFlight = 10
+	}
+	nc := *c
+	nc.shadow = &shadowBackend{config: shadow, slots: make(chan struct{}, shadow.MaxInFlight)}
+	return &nc
+}
+
+// shadowBackend sends the shadow requests.
+type shadowBackend struct {
+	config Shadow
+	slots  chan struct{}
+}
+
+// request sends the query to the primary backend, and to the shadow backend in the background.
+func (s *shadowBackend) request(c *Client, ctx context.Context, query string, variables map[string]interface{}, options ...Option) ([]byte, *http.Response, io.Reader, Errors) {
+	data, resp, respBuf, errs := c.requestWithoutShadow(ctx, query, variables, options...)
+
+	select {
+	case s.slots <- struct{}{}:
+	default:
+		return data, resp, respBuf, errs
+	}
+	httpClient := s.config.HTTPClient
+	if httpClient == nil {
+		httpClient = c.httpClient
+	}
+	// The variables are encoded now, since the caller may change them once the query returns.
+	variables, err := encodeVariables(variables)
+	if err != nil {
+		<-s.slots
+		return data, resp, respBuf, errs
+	}
+	shadow := &Client{url: s.config.URL, httpClient: httpClient, requestModifier: c.requestModifier, tokens: c.tokens, signer: c.signer}
+	primaryErrs := append(Errors(nil), errs...)
+	go func() {
+		defer func() { <-s.slots }()
+		ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+		defer cancel()
+		shadowData, _, _, shadowErrs := shadow.request(ctx, query, variables, options...)
+		diffs := compareResponses(data, primaryErrs, shadowData, shadowErrs)
+		if len(diffs) > 0 && s.config.OnDiff != nil {
+			s.config.OnDiff(query, variables, diffs)
+		}
+	}()
+	return data, resp, respBuf, errs
+}
+
+// encodeVariables returns a copy of the variables, with their values encoded as JSON.
+func encodeVariables(variables map[string]interface{}) (map[string]interface{}, error) {
+	encoded, err := requestVariables(variables)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(encoded)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(b, &values); err != nil {
+		return nil, err
+	}
+	out := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		out[name] = value
+	}
+	return out, nil
+}
+
+// compareResponses returns the differences between the data and errors of two responses.
+func compareResponses(primaryData []byte, primaryErrs Errors, shadowData []byte, shadowErrs Errors) []ShadowDiff {
+	var diffs []ShadowDiff
+	primary, shadow := decodeShadowData(primaryData), decodeShadowData(shadowData)
+	diffJSON("", primary, shadow, &diffs)
+
+	primaryMessages, shadowMessages := errorMessages(primaryErrs), errorMessages(shadowErrs)
+	if !reflect.DeepEqual(primaryMessages, shadowMessages) {
+		a, _ := json.Marshal(primaryMessages)
+		b, _ := json.Marshal(shadowMessages)
+		diffs = append(diffs, ShadowDiff{Path: "errors", Primary: a, Shadow: b})
+	}
+	return diffs
+}
+
+func decodeShadowData(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if d.Decode(&v) != nil {
+		return nil
+	}
+	return v
+}
+
+// errorMessages returns the sorted messages of the errors.
+func errorMessages(errs Errors) []string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Message)
+	}
+	sort.Strings(messages)
+	return messages
+}
+
+// diffJSON appends the differences between the decoded JSON values a and b at path to diffs.
+func diffJSON(path string, a, b interface{}, diffs *[]ShadowDiff) {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		if b, ok := b.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(a)+len(b))
+			for key := range a {
+				keys = append(keys, key)
+			}
+			for key := range b {
+				if _, ok := a[key]; !ok {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				keyPath := key
+				if path != "" {
+					keyPath = path + "." + key
+				}
+				va, okA := a[key]
+				vb, okB := b[key]
+				if !okA || !okB {
+					*diffs = append(*diffs, ShadowDiff{Path: keyPath, Primary: rawJSON(va, okA), Shadow: rawJSON(vb, okB)})
+					continue
+				}
+				diffJSON(keyPath, va, vb, diffs)
+			}
+			return
+		}
+	case []interface{}:
+		if b, ok := b.([]interface{}); ok {
+			for i := 0; i < len(a) || i < len(b); i++ {
+				itemPath := fmt.Sprintf("%s[%d]", path, i)
+				if i >= len(a) || i >= len(b) {
+					*diffs = append(*diffs, ShadowDiff{Path: itemPath, Primary: rawJSONItem(a, i), Shadow: rawJSONItem(b, i)})
+					continue
+				}
+				diffJSON(itemPath, a[i], b[i], diffs)
+			}
+			return
+		}
+	}
+	if !reflect.DeepEqual(a, b) {
+		*diffs = append(*diffs, ShadowDiff{Path: path, Primary: rawJSON(a, true), Shadow: rawJSON(b, true)})
+	}
+}
+
+func rawJSON(v interface{}, ok bool) json.RawMessage {
+	if !ok {
+		return nil
+	}
+	b, _ := json.Marshal(v)
+	return b
+}
+
+func rawJSONItem(items []interface{}, i int) json.RawMessage {
+	if i >= len(items) {
+		return nil
+	}
+	return rawJSON(items[i], true)
+}
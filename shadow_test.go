@@ -0,0 +1,199 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zainirfan13/graphql-client"
+)
+
+func TestClient_WithShadow(t *testing.T) {
+	var shadowRequests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body := mustRead(req.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if req.URL.Host != "shadow" {
+			switch body {
+			case `{"query":"{viewer{login}}"}` + "\n":
+				mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+			default:
+				mustWrite(w, `{"data": {"viewer": {"login": "gopher", "repositories": [{"name": "a"}, {"name": "b"}], "bio": "primary"}}}`)
+			}
+			return
+		}
+		atomic.AddInt32(&shadowRequests, 1)
+		switch body {
+		case `{"query":"{viewer{login}}"}` + "\n":
+			mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+		default:
+			mustWrite(w, `{"data": {"viewer": {"login": "octocat", "repositories": [{"name": "a"}], "email": "octocat@example.com"}}, "errors": [{"message": "bio is deprecated"}]}`)
+		}
+	})
+
+	diffs := make(chan []graphql.ShadowDiff, 1)
+	client := graphql.NewClient("http://primary/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithShadow(graphql.Shadow{
+			URL: "http://shadow/graphql",
+			OnDiff: func(query string, variables map[string]interface{}, d []graphql.ShadowDiff) {
+				diffs <- d
+			},
+		})
+
+	// Identical responses have no difference.
+	var q struct {
+		Viewer struct {
+			Login string
+		}
+	}
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return atomic.LoadInt32(&shadowRequests) == 1 })
+	select {
+	case d := <-diffs:
+		t.Errorf("got differences %v, want none", d)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// The primary result is returned, and the differences are reported.
+	var q2 struct {
+		Viewer struct {
+			Login        string
+			Repositories []struct {
+				Name string
+			}
+			Bio string
+		}
+	}
+	if err := client.Query(context.Background(), &q2, nil); err != nil {
+		t.Fatal(err)
+	}
+	if q2.Viewer.Login != "gopher" || q2.Viewer.Bio != "primary" {
+		t.Errorf("got %+v, want the primary result", q2)
+	}
+	var got []string
+	select {
+	case d := <-diffs:
+		for _, diff := range d {
+			got = append(got, diff.String())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("got no differences")
+	}
+	want := []string{
+		`viewer.bio: "primary" != <missing>`,
+		`viewer.email: <missing> != "octocat@example.com"`,
+		`viewer.login: "gopher" != "octocat"`,
+		`viewer.repositories[1]: {"name":"b"} != <missing>`,
+		`errors: [] != ["bio is deprecated"]`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got differences %q, want %q", got, want)
+	}
+
+	// Mutations aren't shadowed.
+	var m struct {
+		AddStar struct {
+			ID string
+		} `graphql:"addStar(id: \"1\")"`
+	}
+	_ = client.Mutate(context.Background(), &m, nil)
+	time.Sleep(20 * time.Millisecond)
+	if got := atomic.LoadInt32(&shadowRequests); got != 2 {
+		t.Errorf("got %d shadow requests, want 2", got)
+	}
+}
+
+func TestClient_WithShadow_variablesAndTokens(t *testing.T) {
+	shadowRequests := make(chan [2]string, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body := mustRead(req.Body)
+		if req.URL.Host == "shadow" {
+			shadowRequests <- [2]string{body, req.Header.Get("Authorization")}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, `{"data": {"nodes": [{"id": "1"}]}}`)
+	})
+	client := graphql.NewClient("http://primary/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithTokenProvider(graphql.TokenProviderFunc(func(ctx context.Context) (graphql.Token, error) {
+			return graphql.Token{AccessToken: "secret"}, nil
+		})).
+		WithShadow(graphql.Shadow{URL: "http://shadow/graphql"})
+
+	var q struct {
+		Nodes []struct {
+			ID string
+		} `graphql:"nodes(ids: $ids)"`
+	}
+	ids := []graphql.ID{"1"}
+	variables := map[string]interface{}{"ids": ids}
+	if err := client.Query(context.Background(), &q, variables); err != nil {
+		t.Fatal(err)
+	}
+	// The caller may reuse the variables once the query returns.
+	ids[0] = "2"
+	variables["ids"] = nil
+
+	select {
+	case r := <-shadowRequests:
+		if want := `{"query":"query ($ids:[ID!]!){nodes(ids: $ids){id}}","variables":{"ids":["1"]}}` + "\n"; r[0] != want {
+			t.Errorf("got shadow request %s, want %s", r[0], want)
+		}
+		if r[1] != "Bearer secret" {
+			t.Errorf("got Authorization %q, want the token of the client", r[1])
+		}
+	case <-time.After(time.Second):
+		t.Fatal("got no shadow request")
+	}
+}
+
+func TestClient_WithShadow_batching(t *testing.T) {
+	var primaryRequests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body := mustRead(req.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if req.URL.Host == "shadow" {
+			mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+			return
+		}
+		atomic.AddInt32(&primaryRequests, 1)
+		if body[0] != '[' {
+			t.Errorf("got unbatched primary request %s", body)
+			mustWrite(w, `{"data": {"viewer": {"login": "gopher"}}}`)
+			return
+		}
+		mustWrite(w, `[{"data": {"viewer": {"login": "gopher"}}}, {"data": {"viewer": {"login": "gopher"}}}]`)
+	})
+	client := graphql.NewClient("http://primary/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithBatching(graphql.BatchConfig{Window: 50 * time.Millisecond}).
+		WithShadow(graphql.Shadow{URL: "http://shadow/graphql"})
+
+	// The queries of the shadowed client are batched together.
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var q struct {
+				Viewer struct {
+					Login string
+				}
+			}
+			if err := client.Query(context.Background(), &q, nil); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := atomic.LoadInt32(&primaryRequests); got != 1 {
+		t.Errorf("got %d primary requests, want 1 batch", got)
+	}
+}
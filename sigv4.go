@@ -0,0 +1,262 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSCredentials are the credentials of an AWS identity.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is the session token of temporary credentials.
+	SessionToken string
+}
+
+// StaticAWSCredentials returns a provider of the credentials, for SigV4.
+func StaticAWSCredentials(credentials AWSCredentials) func(ctx context.Context) (AWSCredentials, error) {
+	return func(ctx context.Context) (AWSCredentials, error) {
+		return credentials, nil
+	}
+}
+
+// SigV4 signs requests with AWS Signature Version 4, e.g. for AWS AppSync APIs with IAM authorization.
+type SigV4 struct {
+	// Credentials provides the credentials to sign with, e.g. from the credentials provider of the AWS SDK.
+	Credentials func(ctx context.Context) (AWSCredentials, error)
+	// Region is the region of the service, e.g. us-east-1.
+	Region string
+	// Service is the signing name of the service, appsync if empty.
+	Service string
+
+	// now returns the signing time, time.Now if nil.
+	now func() time.Time
+}
+
+// RequestSigner signs the HTTP requests of a client, e.g. SigV4.
+type RequestSigner interface {
+	// Sign signs req, whose body is body.
+	Sign(req *http.Request, body []byte) error
+}
+
+// WithRequestSigner returns a copy of the client that signs its HTTP requests with signer. Each request is signed
+// right before it's sent, once its endpoint is chosen, e.g. by WithFailover or the Endpoints of WithHedging,
+// and once it got its turn from the rate limiter, so that the signature covers the host it's sent to and
+// doesn't expire while it waits. A request that can't be signed, e.g. because the credentials can't be fetched,
+// fails without being sent.
+func (c *Client) WithRequestSigner(signer RequestSigner) *Client {
+	nc := *c
+	nc.signer = signer
+	return &nc
+}
+
+// signingError is the error of a request that couldn't be signed, and wasn't sent.
+type signingError struct {
+	err error
+}
+
+func (e *signingError) Error() string {
+	return fmt.Sprintf("problem signing request: %v", e.err)
+}
+
+func (e *signingError) Unwrap() error {
+	return e.err
+}
+
+// isSigningError reports whether err is the error of a request that couldn't be signed.
+func isSigningError(err error) bool {
+	var signErr *signingError
+	return errors.As(err, &signErr)
+}
+
+// signRequest returns a copy of req signed by the signer of the client.
+func (c *Client) signRequest(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	var body []byte
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, &signingError{err}
+		}
+		body, err = ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, &signingError{err}
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	if err := c.signer.Sign(r, body); err != nil {
+		return nil, &signingError{err}
+	}
+	return r, nil
+}
+
+// sigV4UnsignedHeaders are the headers that aren't signed, since proxies may change them.
+var sigV4UnsignedHeaders = map[string]bool{
+	"authorization":   true,
+	"user-agent":      true,
+	"x-amzn-trace-id": true,
+	"expect":          true,
+}
+
+// Sign signs req, whose body is body, setting its X-Amz-Date, X-Amz-Security-Token and Authorization headers.
+// The host and the headers of req are signed, so they mustn't change after it's signed.
+func (s SigV4) Sign(req *http.Request, body []byte) error {
+	credentials, err := s.Credentials(req.Context())
+	if err != nil {
+		return fmt.Errorf("problem getting AWS credentials: %w", err)
+	}
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	service := s.Service
+	if service == "" {
+		service = "appsync"
+	}
+	t := now().UTC()
+	amzDate, date := t.Format("20060102T150405Z"), t.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if sigV4UnsignedHeaders[name] {
+			continue
+		}
+		trimmed := make([]string, len(values))
+		for i, value := range values {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		headers[name] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	bodyHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		sigV4Path(req.URL.Path),
+		sigV4Query(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+
+	scope := date + "/" + s.Region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + credentials.SecretAccessKey)
+	for _, part := range []string{date, s.Region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		credentials.AccessKeyID, scope, signedHeaders, signature))
+	return nil
+}
+
+// Headers returns the signed headers of an AppSync realtime request of payload to the GraphQL endpoint apiURL.
+func (s SigV4) Headers(ctx context.Context, apiURL string, payload []byte) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json, text/javascript")
+	req.Header.Set("Content-Encoding", "amz-1.0")
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	if err := s.Sign(req, payload); err != nil {
+		return nil, err
+	}
+	headers := map[string]string{
+		"accept":           req.Header.Get("Accept"),
+		"content-encoding": req.Header.Get("Content-Encoding"),
+		"content-type":     req.Header.Get("Content-Type"),
+		"host":             req.URL.Host,
+		"x-amz-date":       req.Header.Get("X-Amz-Date"),
+		"Authorization":    req.Header.Get("Authorization"),
+	}
+	if token := req.Header.Get("X-Amz-Security-Token"); token != "" {
+		headers["X-Amz-Security-Token"] = token
+	}
+	return headers, nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// sigV4Escape escapes s as specified by SigV4: all bytes but the unreserved characters are percent-encoded.
+func sigV4Escape(s string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isLetter(c) || isDigit(c) || c == '-' || c == '_' || c == '.' || c == '~' || (keepSlash && c == '/') {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// sigV4Path returns the canonical URI of the path.
+func sigV4Path(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return sigV4Escape(path, true)
+}
+
+// sigV4Query returns the canonical query string of the query parameters, sorted by name then value.
+func sigV4Query(query map[string][]string) string {
+	type param struct {
+		name, value string
+	}
+	var params []param
+	for name, values := range query {
+		for _, value := range values {
+			params = append(params, param{sigV4Escape(name, false), sigV4Escape(value, false)})
+		}
+	}
+	sort.Slice(params, func(i, j int) bool {
+		if params[i].name != params[j].name {
+			return params[i].name < params[j].name
+		}
+		return params[i].value < params[j].value
+	})
+	pairs := make([]string, len(params))
+	for i, p := range params {
+		pairs[i] = p.name + "=" + p.value
+	}
+	return strings.Join(pairs, "&")
+}
@@ -0,0 +1,207 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var sigV4TestCredentials = StaticAWSCredentials(AWSCredentials{
+	AccessKeyID:     "AKIDEXAMPLE",
+	SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+})
+
+func sigV4TestTime() time.Time {
+	return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+}
+
+// TestSigV4_Sign checks the example of the AWS documentation of Signature Version 4.
+func TestSigV4_Sign(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	signer := SigV4{Credentials: sigV4TestCredentials, Region: "us-east-1", Service: "iam", now: sigV4TestTime}
+	if err := signer.Sign(req, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, " +
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("got Authorization %q, want %q", got, want)
+	}
+	if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Errorf("got X-Amz-Date %q", got)
+	}
+}
+
+// checkSigV4 checks that req is signed by signer, for its host and body.
+func checkSigV4(t *testing.T, signer SigV4, req *http.Request) {
+	t.Helper()
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := req.Clone(req.Context())
+	if err := signer.Sign(r, body); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := req.Header.Get("Authorization"), r.Header.Get("Authorization"); got == "" || got != want {
+		t.Errorf("request to %s: got Authorization %q, want %q", req.URL.Host, got, want)
+	}
+}
+
+func TestClient_WithRequestSigner(t *testing.T) {
+	signer := SigV4{
+		Credentials: StaticAWSCredentials(AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "session"}),
+		Region:      "us-east-1",
+		now:         sigV4TestTime,
+	}
+	var hosts []string
+	client := NewClient("https://unused/graphql", &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		hosts = append(hosts, req.URL.Host)
+		if got, want := req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/20150830/us-east-1/appsync/aws4_request, SignedHeaders=content-type;host;x-amz-date;x-amz-security-token, Signature="; !strings.HasPrefix(got, want) {
+			t.Errorf("got Authorization %q, want prefix %q", got, want)
+		}
+		if got := req.Header.Get("X-Amz-Security-Token"); got != "session" {
+			t.Errorf("got X-Amz-Security-Token %q", got)
+		}
+		checkSigV4(t, signer, req)
+		if req.URL.Host == "a" {
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable", Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(`{"data": {"viewer": {"login": "gopher"}}}`))}, nil
+	})}).
+		WithFailover(Failover{Resolver: StaticEndpoints{"https://a/graphql", "https://b/graphql"}}).
+		WithRequestSigner(signer)
+
+	// Each endpoint is signed for its own host.
+	var q struct {
+		Viewer struct {
+			Login string
+		}
+	}
+	if err := client.Query(context.Background(), &q, nil); err != nil {
+		t.Fatal(err)
+	}
+	if q.Viewer.Login != "gopher" || !reflect.DeepEqual(hosts, []string{"a", "b"}) {
+		t.Errorf("got login %q from hosts %v, want gopher from a and b", q.Viewer.Login, hosts)
+	}
+}
+
+func TestClient_WithRequestSigner_rateLimit(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		signedAt []time.Time
+	)
+	signer := SigV4{Credentials: sigV4TestCredentials, Region: "us-east-1", now: func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		signedAt = append(signedAt, time.Now())
+		return sigV4TestTime()
+	}}
+	client := NewClient("https://example.appsync-api.us-east-1.amazonaws.com/graphql", &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(`{"data": {"viewer": {"login": "gopher"}}}`))}, nil
+	})}).
+		WithRateLimit(RateLimit{Rate: 5, Burst: 1}).
+		WithRequestSigner(signer)
+
+	// The second request waits for its turn, and is signed once it got it.
+	var q struct {
+		Viewer struct {
+			Login string
+		}
+	}
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if err := client.Query(context.Background(), &q, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(signedAt) != 2 || signedAt[1].Sub(start) < 150*time.Millisecond {
+		t.Errorf("got requests signed at %v after the start, want the second after its turn", signedAt)
+	}
+}
+
+func TestClient_WithRequestSigner_failure(t *testing.T) {
+	signer := SigV4{Region: "us-east-1", Credentials: func(ctx context.Context) (AWSCredentials, error) {
+		return AWSCredentials{}, errors.New("expired credentials")
+	}}
+	var requests int
+	client := NewClient("https://unused/graphql", &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(`{"data": {"viewer": {"login": "gopher"}}}`))}, nil
+	})}).
+		WithFailover(Failover{Resolver: StaticEndpoints{"https://a/graphql", "https://b/graphql"}}).
+		WithRequestSigner(signer)
+
+	// The request fails without being sent unsigned, nor failing over.
+	var q struct {
+		Viewer struct {
+			Login string
+		}
+	}
+	err := client.Query(context.Background(), &q, nil)
+	if err == nil || !strings.Contains(err.Error(), "problem signing request: problem getting AWS credentials: expired credentials") {
+		t.Errorf("got error %v, want the signing error", err)
+	}
+	if requests != 0 {
+		t.Errorf("got %d requests, want none", requests)
+	}
+	if order := client.endpoints.order([]string{"https://a/graphql", "https://b/graphql"}); order[0] != "https://a/graphql" {
+		t.Errorf("got endpoints %v, want a still healthy", order)
+	}
+}
+
+func TestClient_WithRequestSigner_tokenProvider(t *testing.T) {
+	signer := SigV4{Credentials: sigV4TestCredentials, Region: "us-east-1", now: sigV4TestTime}
+	var fetches, requests int32
+	client := NewClient("https://example.appsync-api.us-east-1.amazonaws.com/graphql", &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(`{"data": {"viewer": {"login": "gopher"}}}`))}, nil
+	})}).WithRequestSigner(signer).WithTokenProvider(counterTokenProvider(&fetches, 0))
+
+	var q struct {
+		Viewer struct {
+			Login string
+		}
+	}
+	if err := client.Query(context.Background(), &q, nil); !errors.Is(err, ErrSignedRequest) {
+		t.Errorf("got error %v, want %v", err, ErrSignedRequest)
+	}
+	if fetches != 0 || requests != 0 {
+		t.Errorf("got %d token fetches and %d requests, want none", fetches, requests)
+	}
+}
+
+func TestSigV4_Headers(t *testing.T) {
+	signer := SigV4{Credentials: sigV4TestCredentials, Region: "us-east-1", now: sigV4TestTime}
+	headers, err := signer.Headers(context.Background(), "https://example.appsync-api.us-east-1.amazonaws.com/graphql", []byte("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(headers)
+	for _, want := range []string{
+		`"accept":"application/json, text/javascript"`,
+		`"content-encoding":"amz-1.0"`,
+		`"content-type":"application/json; charset=UTF-8"`,
+		`"host":"example.appsync-api.us-east-1.amazonaws.com"`,
+		`"x-amz-date":"20150830T123600Z"`,
+		`"Authorization":"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/appsync/aws4_request, SignedHeaders=accept;content-encoding;content-type;host;x-amz-date, Signature=`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("got headers %s, want %s", b, want)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// QuerySplitting configures the splitting of queries over a budget into several requests.
+type QuerySplitting struct {
+	// Budget is the budget of each request. MaxDepth is ignored,
+	// since splitting doesn't change the depth of root fields.
+	Budget ComplexityBudget
+	// Parallel sends the requests of a split query concurrently, instead of one after the other.
+	Parallel bool
+}
+
+// WithQuerySplitting returns a copy of the client that splits the queries that are over the budget
+// at root-field boundaries into several requests, and merges their data and errors back into one response.
+// Root fields are packed into as few requests as the budget allows; a root field over the budget is sent alone.
+//
+// Mutations are never split, since their root fields must run in order in a single request,
+// and neither are the pre-built queries of Exec.
+func (c *Client) WithQuerySplitting(splitting QuerySplitting) *Client {
+	nc := *c
+	nc.querySplitting = &splitting
+	return &nc
+}
+
+// queryPart is a part of a split query.
+type queryPart struct {
+	query     string
+	variables []string
+}
+
+// splitQuery splits the operation query at its root fields into queries within budget.
+// It returns nil if the query doesn't need to, or can't, be split.
+func splitQuery(query string, variables map[string]interface{}, budget ComplexityBudget) ([]queryPart, error) {
+	doc, err := parseDocument(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.operations) != 1 || len(doc.fragments) > 0 || len(doc.operation.selections) < 2 {
+		return nil, nil
+	}
+	budget.MaxDepth = 0
+	a := newComplexityAnalyzer(doc, doc.operation, variables, budget.Model)
+
+	var (
+		parts  [][]*astSelection
+		part   []*astSelection
+		total  Complexity
+		splits bool
+	)
+	for _, s := range doc.operation.selections {
+		var c Complexity
+		c.Cost, err = a.selectionCost([]*astSelection{s}, "", 1, &c)
+		if err != nil {
+			return nil, err
+		}
+		sum := Complexity{Fields: total.Fields + c.Fields, Cost: total.Cost + c.Cost}
+		if len(part) > 0 && budget.check(sum) != nil {
+			parts = append(parts, part)
+			part, total, splits = nil, Complexity{}, true
+			sum = c
+		}
+		part = append(part, s)
+		total = sum
+	}
+	if !splits {
+		return nil, nil
+	}
+	parts = append(parts, part)
+
+	result := make([]queryPart, len(parts))
+	for i, selections := range parts {
+		result[i] = buildQueryPart(doc.operation, selections)
+	}
+	return result, nil
+}
+
+// buildQueryPart writes the operation op with the root selections only.
+// The directives of the operation are kept in every part.
+func buildQueryPart(op *astOperation, selections []*astSelection) queryPart {
+	used := make(map[string]bool)
+	for _, name := range op.directiveVariables {
+		used[name] = true
+	}
+	var fields []string
+	for _, s := range selections {
+		fields = append(fields, s.text)
+		for _, name := range s.variables {
+			used[name] = true
+		}
+	}
+	var part queryPart
+	var definitions []string
+	for _, d := range op.definitions {
+		if used[d.name] {
+			definitions = append(definitions, d.text)
+			part.variables = append(part.variables, d.name)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(op.header)
+	if len(definitions) > 0 {
+		if op.header == "" {
+			b.WriteString("query ")
+		}
+		b.WriteString("(")
+		b.WriteString(strings.Join(definitions, ""))
+		b.WriteString(")")
+	}
+	b.WriteString(op.directives)
+	b.WriteString("{")
+	b.WriteString(strings.Join(fields, ","))
+	b.WriteString("}")
+	part.query = b.String()
+	return part
+}
+
+// splitRequest sends query, split into several requests if it's over the budget of the client,
+// and merges their responses.
+func (c *Client) splitRequest(ctx context.Context, query string, variables map[string]interface{}, options ...Option) ([]byte, *http.Response, io.Reader, Errors) {
+	encoded, err := requestVariables(variables)
+	if err != nil {
+		return nil, nil, nil, Errors{newError(ErrGraphQLEncode, err)}
+	}
+	parts, err := splitQuery(query, encoded, c.querySplitting.Budget)
+	if err != nil || len(parts) == 0 {
+		// Let the server report syntax errors.
+		return c.request(ctx, query, variables, options...)
+	}
+
+	type response struct {
+		data    []byte
+		resp    *http.Response
+		respBuf io.Reader
+		errs    Errors
+	}
+	responses := make([]response, len(parts))
+	send := func(i int) {
+		vars := make(map[string]interface{}, len(parts[i].variables))
+		for _, name := range parts[i].variables {
+			if value, ok := variables[name]; ok {
+				vars[name] = value
+			}
+		}
+		r := &responses[i]
+		r.data, r.resp, r.respBuf, r.errs = c.request(ctx, parts[i].query, vars, options...)
+	}
+	if c.querySplitting.Parallel {
+		var wg sync.WaitGroup
+		for i := range parts {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				send(i)
+			}(i)
+		}
+		wg.Wait()
+	} else {
+		for i := range parts {
+			send(i)
+		}
+	}
+
+	var (
+		data    json.RawMessage
+		resp    *http.Response
+		respBuf io.Reader
+		errs    Errors
+	)
+	for _, r := range responses {
+		errs = append(errs, r.errs...)
+		if r.resp != nil {
+			resp, respBuf = r.resp, r.respBuf
+		}
+		if len(r.data) == 0 || bytes.Equal(r.data, []byte("null")) {
+			continue
+		}
+		if data == nil {
+			data = r.data
+			continue
+		}
+		merged, err := mergeJSONObjects(data, r.data)
+		if err != nil {
+			errs = append(errs, newError(ErrJsonDecode, err))
+			continue
+		}
+		data = merged
+	}
+	return data, resp, respBuf, errs
+}
+
+// mergeJSONObjects merges the JSON objects a and b, recursively for the keys of objects in both.
+// For other values, b takes precedence.
+func mergeJSONObjects(a, b json.RawMessage) (json.RawMessage, error) {
+	var objA, objB map[string]json.RawMessage
+	if json.Unmarshal(a, &objA) != nil || json.Unmarshal(b, &objB) != nil || objA == nil || objB == nil {
+		return b, nil
+	}
+	for key, value := range objB {
+		if existing, ok := objA[key]; ok {
+			merged, err := mergeJSONObjects(existing, value)
+			if err != nil {
+				return nil, err
+			}
+			value = merged
+		}
+		objA[key] = value
+	}
+	return json.Marshal(objA)
+}
@@ -0,0 +1,116 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/zainirfan13/graphql-client"
+)
+
+func TestClient_WithQuerySplitting(t *testing.T) {
+	responses := map[string]string{
+		`{"query":"query ($login:String!){viewer{login},user(login: $login){name}}","variables":{"login":"gopher"}}` + "\n": `{"data": {"viewer": {"login": "me"}, "user": {"name": "Gopher"}}}`,
+		`{"query":"query ($first:Int!){repositories(first: $first){totalCount}}","variables":{"first":50}}` + "\n":          `{"data": {"repositories": {"totalCount": 3}}, "errors": [{"message": "partial", "path": ["repositories"]}]}`,
+	}
+	for _, parallel := range []bool{false, true} {
+		var mu sync.Mutex
+		var bodies []string
+		mux := http.NewServeMux()
+		mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+			body := mustRead(req.Body)
+			mu.Lock()
+			bodies = append(bodies, body)
+			mu.Unlock()
+			resp, ok := responses[body]
+			if !ok {
+				t.Errorf("unexpected body: %s", body)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			mustWrite(w, resp)
+		})
+		client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+			WithQuerySplitting(graphql.QuerySplitting{
+				Budget:   graphql.ComplexityBudget{MaxCost: 50},
+				Parallel: parallel,
+			})
+
+		var q struct {
+			Viewer struct {
+				Login string
+			}
+			User struct {
+				Name string
+			} `graphql:"user(login: $login)"`
+			Repositories struct {
+				TotalCount int
+			} `graphql:"repositories(first: $first)"`
+		}
+		err := client.Query(context.Background(), &q, map[string]interface{}{"login": "gopher", "first": 50})
+		if err == nil || err.Error() != "Message: partial, Locations: []" {
+			t.Errorf("parallel %v: got error: %v, want the error of the second request", parallel, err)
+		}
+		if q.Viewer.Login != "me" || q.User.Name != "Gopher" || q.Repositories.TotalCount != 3 {
+			t.Errorf("parallel %v: got merged data: %+v", parallel, q)
+		}
+		if len(bodies) != 2 {
+			t.Errorf("parallel %v: got %d requests, want 2", parallel, len(bodies))
+		}
+
+		// Mutations are sent as is.
+		bodies = nil
+		var m struct {
+			A struct{ ID string } `graphql:"a: addStar(first: $first)"`
+			B struct{ ID string } `graphql:"b: addStar(first: $first)"`
+		}
+		responses[`{"query":"mutation ($first:Int!){a: addStar(first: $first){id},b: addStar(first: $first){id}}","variables":{"first":50}}`+"\n"] = `{"data": {"a": {"id": "1"}, "b": {"id": "2"}}}`
+		if err := client.Mutate(context.Background(), &m, map[string]interface{}{"first": 50}); err != nil {
+			t.Errorf("parallel %v: %v", parallel, err)
+		}
+		if len(bodies) != 1 || m.A.ID != "1" || m.B.ID != "2" {
+			t.Errorf("parallel %v: got %d requests and %+v, want 1 request", parallel, len(bodies), m)
+		}
+	}
+}
+
+type operationDirective string
+
+func (d operationDirective) Type() graphql.OptionType { return graphql.OptionTypeOperationDirective }
+func (d operationDirective) String() string           { return string(d) }
+
+func TestClient_WithQuerySplitting_operationDirectives(t *testing.T) {
+	responses := map[string]string{
+		`{"query":"query Q($ttl:Int!)@cached(ttl: $ttl){viewer{login}}","variables":{"ttl":60}}` + "\n":                                            `{"data": {"viewer": {"login": "me"}}}`,
+		`{"query":"query Q($login:String!$ttl:Int!)@cached(ttl: $ttl){user(login: $login){name}}","variables":{"login":"gopher","ttl":60}}` + "\n": `{"data": {"user": {"name": "Gopher"}}}`,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, req *http.Request) {
+		body := mustRead(req.Body)
+		resp, ok := responses[body]
+		if !ok {
+			t.Errorf("unexpected body: %s", body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		mustWrite(w, resp)
+	})
+	client := graphql.NewClient("/graphql", &http.Client{Transport: localRoundTripper{handler: mux}}).
+		WithQuerySplitting(graphql.QuerySplitting{Budget: graphql.ComplexityBudget{MaxFields: 2}})
+
+	var q struct {
+		Viewer struct {
+			Login string
+		}
+		User struct {
+			Name string
+		} `graphql:"user(login: $login)"`
+	}
+	err := client.Query(context.Background(), &q, map[string]interface{}{"login": "gopher", "ttl": 60},
+		graphql.OperationName("Q"), operationDirective("@cached(ttl: $ttl)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Viewer.Login != "me" || q.User.Name != "Gopher" {
+		t.Errorf("got merged data: %+v", q)
+	}
+}
@@ -30,6 +30,8 @@ const (
 	GQL_CONNECTION_ERROR OperationMessageType = "conn_err"
 	// Client sends this message to execute GraphQL operation
 	GQL_START OperationMessageType = "start"
+	// AWS AppSync sends this message to acknowledge a GQL_START message
+	GQL_START_ACK OperationMessageType = "start_ack"
 	// Client sends this message in order to stop a running GraphQL operation execution (for example: unsubscribe)
 	GQL_STOP OperationMessageType = "stop"
 	// Server sends this message upon a failing operation, before the GraphQL execution, usually due to GraphQL validation errors (resolver errors are part of GQL_DATA message, and will be added as errors array)
@@ -109,6 +111,10 @@ type SubscriptionClient struct {
 	onError          func(sc *SubscriptionClient, err error) error
 	errorChan        chan error
 	disabledLogTypes []OperationMessageType
+	endpointResolver EndpointResolver
+	endpoint         string // the endpoint of the resolver the client sticks to
+	tokens           *tokenCache
+	appSync          *AppSync
 }
 
 func NewSubscriptionClient(url string) *SubscriptionClient {
@@ -123,8 +129,11 @@ func NewSubscriptionClient(url string) *SubscriptionClient {
 	}
 }
 
-// GetURL returns GraphQL server's URL
+// GetURL returns GraphQL server's URL, i.e. the current endpoint if there is an endpoint resolver
 func (sc *SubscriptionClient) GetURL() string {
+	if sc.endpoint != "" {
+		return sc.endpoint
+	}
 	return sc.url
 }
 
@@ -170,6 +179,26 @@ func (sc *SubscriptionClient) WithRetryTimeout(timeout time.Duration) *Subscript
 	return sc
 }
 
+// WithEndpointResolver sets the resolver of the endpoints to connect to, instead of the URL of the client.
+// The client sticks to the endpoint it connected to, also when it reconnects, and moves to the next endpoint
+// of the resolver only if it fails to connect. The URL is used if the resolver returns no endpoint
+func (sc *SubscriptionClient) WithEndpointResolver(resolver EndpointResolver) *SubscriptionClient {
+	sc.endpointResolver = resolver
+	sc.endpoint = ""
+	return sc
+}
+
+// WithTokenProvider sets the provider of the tokens that authorize the connections. A fresh token is fetched
+// on every connection and reconnection, and put in the Authorization param of the connection_init message,
+// along with the connection params.
+func (sc *SubscriptionClient) WithTokenProvider(provider TokenProvider) *SubscriptionClient {
+	sc.tokens = nil
+	if provider != nil {
+		sc.tokens = newTokenCache(provider)
+	}
+	return sc
+}
+
 // WithLog sets loging function to print out received messages. By default, nothing is printed
 func (sc *SubscriptionClient) WithLog(logger func(args ...interface{})) *SubscriptionClient {
 	sc.log = logger
@@ -222,15 +251,22 @@ func (sc *SubscriptionClient) init() error {
 	ctx, cancel := context.WithCancel(context.Background())
 	sc.context = ctx
 	sc.cancel = cancel
+	if sc.tokens != nil {
+		sc.tokens.clear()
+	}
 
+	failed := ""
 	for {
 		var err error
 		var conn WebsocketConn
 		// allow custom websocket client
 		if sc.conn == nil {
+			sc.resolveEndpoint(failed)
 			conn, err = sc.createConn(sc)
 			if err == nil {
 				sc.conn = conn
+			} else {
+				failed = sc.GetURL()
 			}
 		}
 
@@ -255,6 +291,29 @@ func (sc *SubscriptionClient) init() error {
 	}
 }
 
+// resolveEndpoint sets the endpoint to connect to: the current endpoint, unless the client failed
+// to connect to the failed endpoint, in which case the next endpoint of the resolver.
+func (sc *SubscriptionClient) resolveEndpoint(failed string) {
+	if sc.endpointResolver == nil || (failed == "" && sc.endpoint != "") {
+		return
+	}
+	endpoints, err := sc.endpointResolver.Resolve(sc.context)
+	if err != nil {
+		sc.printLog(fmt.Sprintf("problem resolving endpoints: %s", err.Error()), "client", GQL_INTERNAL)
+	}
+	if len(endpoints) == 0 {
+		return
+	}
+	next := endpoints[0]
+	for i, endpoint := range endpoints {
+		if endpoint == failed {
+			next = endpoints[(i+1)%len(endpoints)]
+			break
+		}
+	}
+	sc.endpoint = next
+}
+
 func (sc *SubscriptionClient) writeJSON(v interface{}) error {
 	if sc.conn != nil {
 		return sc.conn.WriteJSON(v)
@@ -277,9 +336,21 @@ func (sc *SubscriptionClient) printLog(message interface{}, source string, opTyp
 
 func (sc *SubscriptionClient) sendConnectionInit() (err error) {
 	var bParams []byte = nil
-	if sc.connectionParams != nil {
+	params := sc.connectionParams
+	if sc.tokens != nil {
+		token, err := sc.tokens.get(sc.context, "")
+		if err != nil {
+			return err
+		}
+		params = make(map[string]interface{}, len(sc.connectionParams)+1)
+		for key, value := range sc.connectionParams {
+			params[key] = value
+		}
+		params["Authorization"] = token.authorization()
+	}
+	if params != nil {
 
-		bParams, err = json.Marshal(sc.connectionParams)
+		bParams, err = json.Marshal(params)
 		if err != nil {
 			return
 		}
@@ -321,6 +392,10 @@ func (sc *SubscriptionClient) Exec(query string, variables map[string]interface{
 }
 
 func (sc *SubscriptionClient) do(v interface{}, variables map[string]interface{}, handler func(message []byte, err error) error, options ...Option) (string, error) {
+	variables, options, err := withOptionVariables(variables, options, nil)
+	if err != nil {
+		return "", err
+	}
 	query, err := ConstructSubscription(v, variables, options...)
 	if err != nil {
 		return "", err
@@ -332,6 +407,10 @@ func (sc *SubscriptionClient) do(v interface{}, variables map[string]interface{}
 func (sc *SubscriptionClient) doRaw(query string, variables map[string]interface{}, handler func(message []byte, err error) error) (string, error) {
 	id := uuid.New().String()
 
+	variables, err := requestVariables(variables)
+	if err != nil {
+		return "", err
+	}
 	sub := subscription{
 		query:     query,
 		variables: variables,
@@ -357,16 +436,19 @@ func (sc *SubscriptionClient) startSubscription(id string, sub *subscription) er
 	if sub == nil || sub.started {
 		return nil
 	}
-	extensions_ := `"extensions": {    "authorization": {      "host": "realtime.api.defined.fi",      "Authorization": "cy7pgfKgsMa8VZKEuDXSt5fvYtEIgxoc8XSz2jbx"    }  }`
-	in := struct {
-		Data     string                 `json:"data"`
-		Extensions string `json:"extensions"`
-	}{
-		Query:     sub.query,
-		Extensions: extensions_,
+	var payload []byte
+	var err error
+	if sc.appSync != nil {
+		payload, err = sc.appSync.startPayload(sc.GetContext(), sc.GetURL(), sub)
+	} else {
+		payload, err = json.Marshal(struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables,omitempty"`
+		}{
+			Query:     sub.query,
+			Variables: sub.variables,
+		})
 	}
-
-	payload, err := json.Marshal(in)
 	if err != nil {
 		return err
 	}
@@ -494,6 +576,8 @@ func (sc *SubscriptionClient) Run() error {
 					sc.Unsubscribe(message.ID)
 				case GQL_CONNECTION_KEEP_ALIVE:
 					sc.printLog(message, "server", GQL_CONNECTION_KEEP_ALIVE)
+				case GQL_START_ACK:
+					sc.printLog(message, "server", GQL_START_ACK)
 				case GQL_CONNECTION_ACK:
 					sc.printLog(message, "server", GQL_CONNECTION_ACK)
 					if sc.onConnected != nil {
@@ -665,7 +749,15 @@ func newWebsocketConn(sc *SubscriptionClient) (WebsocketConn, error) {
 		HTTPClient:   sc.websocketOptions.HTTPClient,
 	}
 
-	c, _, err := websocket.Dial(sc.GetContext(), sc.GetURL(), options)
+	url := sc.GetURL()
+	if sc.appSync != nil {
+		var err error
+		if url, err = sc.appSync.connectionURL(sc.GetContext(), url); err != nil {
+			return nil, err
+		}
+	}
+
+	c, _, err := websocket.Dial(sc.GetContext(), url, options)
 	if err != nil {
 		return nil, err
 	}
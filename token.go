@@ -0,0 +1,183 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrSignedRequest is returned for the requests of a client with both a token provider and a request signer,
+// since the token would replace the signature of the requests.
+var ErrSignedRequest = errors.New("signed requests can't be authorized by a token provider")
+
+// Token is an access token, e.g. an OAuth2 access token.
+type Token struct {
+	AccessToken string
+	// TokenType is the type of the token, Bearer if empty.
+	TokenType string
+	// Expiry is the time at which the token expires. Zero means that it doesn't expire.
+	Expiry time.Time
+}
+
+// authorization returns the value of the Authorization header of the token.
+func (t Token) authorization() string {
+	tokenType := t.TokenType
+	if tokenType == "" {
+		tokenType = "Bearer"
+	}
+	return tokenType + " " + t.AccessToken
+}
+
+// tokenExpiryDelta is the time before its expiry at which a token is refreshed,
+// so that it doesn't expire in flight.
+const tokenExpiryDelta = 10 * time.Second
+
+func (t Token) expired() bool {
+	return !t.Expiry.IsZero() && time.Now().Add(tokenExpiryDelta).After(t.Expiry)
+}
+
+// TokenProvider provides the access tokens of a client.
+type TokenProvider interface {
+	// Token fetches a new token.
+	Token(ctx context.Context) (Token, error)
+}
+
+// TokenProviderFunc is a function that provides tokens.
+type TokenProviderFunc func(ctx context.Context) (Token, error)
+
+// Token calls f.
+func (f TokenProviderFunc) Token(ctx context.Context) (Token, error) {
+	return f(ctx)
+}
+
+// tokenCache caches the token of a provider until it expires.
+type tokenCache struct {
+	provider TokenProvider
+	mu       sync.Mutex
+	token    *Token
+}
+
+func newTokenCache(provider TokenProvider) *tokenCache {
+	return &tokenCache{provider: provider}
+}
+
+// get returns the cached token, or fetches a new one if it expired or was rejected.
+func (t *tokenCache) get(ctx context.Context, rejected string) (Token, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token != nil && !t.token.expired() && (rejected == "" || t.token.AccessToken != rejected) {
+		return *t.token, nil
+	}
+	token, err := t.provider.Token(ctx)
+	if err != nil {
+		return Token{}, fmt.Errorf("problem getting token: %w", err)
+	}
+	t.token = &token
+	return token, nil
+}
+
+// clear clears the cached token, so that the next token is fetched.
+func (t *tokenCache) clear() {
+	t.mu.Lock()
+	t.token = nil
+	t.mu.Unlock()
+}
+
+// WithTokenProvider returns a copy of the client that authorizes its requests with the tokens of the provider,
+// in the Authorization header. Tokens are fetched lazily, and cached until they expire. When a request is rejected
+// with a 401 response or an UNAUTHENTICATED error code, the token is refreshed and the request is retried once.
+//
+// It can't be combined with WithRequestSigner: the requests of a client with both fail with ErrSignedRequest.
+func (c *Client) WithTokenProvider(provider TokenProvider) *Client {
+	nc := *c
+	nc.tokens = nil
+	if provider != nil {
+		nc.tokens = newTokenCache(provider)
+	}
+	return &nc
+}
+
+// authorizedRoundTrip sends req with the token of the client if any, refreshing the token
+// and retrying once if it's rejected.
+func (c *Client) authorizedRoundTrip(req *http.Request) (*http.Response, error) {
+	if c.tokens == nil {
+		return c.roundTrip(req)
+	}
+	if c.signer != nil {
+		return nil, ErrSignedRequest
+	}
+	ctx := req.Context()
+	token, err := c.tokens.get(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.roundTripWithToken(req, token)
+	if err != nil || !unauthenticated(resp) {
+		return resp, err
+	}
+	resp.Body.Close()
+
+	if token, err = c.tokens.get(ctx, token.AccessToken); err != nil {
+		return nil, err
+	}
+	return c.roundTripWithToken(req, token)
+}
+
+// roundTripWithToken sends a copy of req with the Authorization header of token.
+func (c *Client) roundTripWithToken(req *http.Request, token Token) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		var err error
+		if r.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	r.Header.Set("Authorization", token.authorization())
+	return c.roundTrip(r)
+}
+
+// unauthenticated reports whether the response rejects the token of its request,
+// with a 401 status or an UNAUTHENTICATED error code.
+func unauthenticated(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusUnauthorized {
+		return true
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+	body, err := readBody(resp)
+	if err != nil || body == nil {
+		return false
+	}
+	type response struct {
+		Errors []struct {
+			Extensions struct {
+				Code string `json:"code"`
+			} `json:"extensions"`
+		} `json:"errors"`
+	}
+	var responses []response
+	if body[0] == '[' {
+		if json.Unmarshal(body, &responses) != nil {
+			return false
+		}
+	} else {
+		var r response
+		if json.Unmarshal(body, &r) != nil {
+			return false
+		}
+		responses = append(responses, r)
+	}
+	for _, r := range responses {
+		for _, e := range r.Errors {
+			if e.Extensions.Code == "UNAUTHENTICATED" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// counterTokenProvider provides the tokens t1, t2, and so on.
+func counterTokenProvider(fetches *int32, expiry time.Duration) TokenProvider {
+	return TokenProviderFunc(func(ctx context.Context) (Token, error) {
+		n := atomic.AddInt32(fetches, 1)
+		token := Token{AccessToken: fmt.Sprintf("t%d", n)}
+		if expiry > 0 {
+			token.Expiry = time.Now().Add(expiry)
+		}
+		return token, nil
+	})
+}
+
+func TestClient_WithTokenProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		rejected func(req *http.Request) *http.Response
+	}{
+		{
+			name: "401",
+			rejected: func(req *http.Request) *http.Response {
+				return &http.Response{StatusCode: http.StatusUnauthorized, Status: "401 Unauthorized", Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(""))}
+			},
+		},
+		{
+			name: "UNAUTHENTICATED",
+			rejected: func(req *http.Request) *http.Response {
+				return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(
+					`{"data": null, "errors": [{"message": "token expired", "extensions": {"code": "UNAUTHENTICATED"}}]}`))}
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var fetches, requests int32
+			client := NewClient("/graphql", &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				atomic.AddInt32(&requests, 1)
+				if req.Header.Get("Authorization") != "Bearer t2" {
+					return tc.rejected(req), nil
+				}
+				body, _ := ioutil.ReadAll(req.Body)
+				if string(body) != `{"query":"{viewer{login}}"}`+"\n" {
+					t.Errorf("got body %q", body)
+				}
+				return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(`{"data": {"viewer": {"login": "gopher"}}}`))}, nil
+			})}).WithTokenProvider(counterTokenProvider(&fetches, 0))
+
+			var q struct {
+				Viewer struct {
+					Login string
+				}
+			}
+			for i := 0; i < 2; i++ {
+				if err := client.Query(context.Background(), &q, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if q.Viewer.Login != "gopher" {
+				t.Errorf("got login %q", q.Viewer.Login)
+			}
+			// The token is refreshed once, and cached.
+			if fetches != 2 || requests != 3 {
+				t.Errorf("got %d token fetches and %d requests, want 2 and 3", fetches, requests)
+			}
+		})
+	}
+}
+
+func TestClient_WithTokenProvider_retryOnce(t *testing.T) {
+	var fetches, requests int32
+	client := NewClient("/graphql", &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&requests, 1)
+		return &http.Response{StatusCode: http.StatusUnauthorized, Status: "401 Unauthorized", Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+	})}).WithTokenProvider(counterTokenProvider(&fetches, time.Minute))
+
+	var q struct {
+		Viewer struct {
+			Login string
+		}
+	}
+	err := client.Query(context.Background(), &q, nil)
+	if err == nil || !strings.Contains(err.Error(), "401 Unauthorized") {
+		t.Errorf("got error %v, want 401 Unauthorized", err)
+	}
+	if fetches != 2 || requests != 2 {
+		t.Errorf("got %d token fetches and %d requests, want 2 and 2", fetches, requests)
+	}
+}
+
+func TestTokenCache_expiry(t *testing.T) {
+	var fetches int32
+	cache := newTokenCache(counterTokenProvider(&fetches, tokenExpiryDelta/2))
+	for i := 1; i <= 2; i++ {
+		token, err := cache.get(context.Background(), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The token expires soon, so it's refreshed.
+		if want := fmt.Sprintf("t%d", i); token.AccessToken != want {
+			t.Errorf("got token %s, want %s", token.AccessToken, want)
+		}
+	}
+}
+
+type tokenTestConn struct {
+	endpointTestConn
+	messages []OperationMessage
+}
+
+func (c *tokenTestConn) WriteJSON(v interface{}) error {
+	c.messages = append(c.messages, v.(OperationMessage))
+	return nil
+}
+
+func TestSubscriptionClient_WithTokenProvider(t *testing.T) {
+	var fetches int32
+	conn := &tokenTestConn{}
+	sc := NewSubscriptionClient("ws://localhost/graphql").
+		WithConnectionParams(map[string]interface{}{"foo": "bar"}).
+		WithTokenProvider(counterTokenProvider(&fetches, time.Hour)).
+		WithWebSocket(func(sc *SubscriptionClient) (WebsocketConn, error) {
+			return conn, nil
+		})
+
+	// A fresh token is sent on every connection.
+	for i := 1; i <= 2; i++ {
+		sc.conn = nil
+		if err := sc.init(); err != nil {
+			t.Fatal(err)
+		}
+		var params map[string]interface{}
+		if err := json.Unmarshal(conn.messages[len(conn.messages)-1].Payload, &params); err != nil {
+			t.Fatal(err)
+		}
+		if params["foo"] != "bar" || params["Authorization"] != fmt.Sprintf("Bearer t%d", i) {
+			t.Errorf("got connection params %v", params)
+		}
+	}
+	if _, ok := sc.connectionParams["Authorization"]; ok {
+		t.Error("got the token in the connection params of the client")
+	}
+}
@@ -1,5 +1,9 @@
 package graphql
 
+import (
+	"reflect"
+)
+
 // GraphQLType interface is used to specify the GraphQL type associated
 // with a particular type. If a type implements this interface, the name of
 // the variable used while creating the GraphQL query will be the output of
@@ -13,3 +17,97 @@ package graphql
 type GraphQLType interface {
 	GetGraphQLType() string
 }
+
+// GraphQLNullable interface is implemented by value types that represent
+// a nullable GraphQL type, such as the Null variants of the scalars package.
+// Variables of these types are declared without "!" at the end, like pointers.
+//
+// As GetGraphQLType, the IsGraphQLNullable function is applied to the zero value of the type.
+type GraphQLNullable interface {
+	IsGraphQLNullable() bool
+}
+
+// ScalarTypes maps Go types to the names of GraphQL scalars. It is useful for
+// types that can't implement GraphQLType, such as time.Time or the types of
+// third-party packages.
+//
+// Variables of a registered type are declared with its scalar name, and struct
+// fields of a registered type aren't expanded into sub-selections when building
+// the query, as if they had the `scalar:"true"` tag. Pointers, slices and arrays
+// of a registered type are handled as usual, e.g. a []uuid.UUID variable is
+// declared as [UUID!]! if uuid.UUID is registered as UUID.
+type ScalarTypes map[reflect.Type]string
+
+// Register maps the type of v to the GraphQL scalar name and returns st.
+// If v is a pointer, the type it points to is registered,
+// so that (*T)(nil) can be used for types that don't have a useful zero value.
+// If st is nil, a new registry is allocated and returned. Register panics if v is nil.
+func (st ScalarTypes) Register(v interface{}, name string) ScalarTypes {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		panic("graphql: Register of nil scalar type " + name)
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if st == nil {
+		st = ScalarTypes{}
+	}
+	st[t] = name
+	return st
+}
+
+// lookup returns the scalar name of t, if t is registered.
+func (st ScalarTypes) lookup(t reflect.Type) (string, bool) {
+	name, ok := st[t]
+	return name, ok
+}
+
+// merge returns a new registry with the types of st and other.
+// The names of other take precedence.
+func (st ScalarTypes) merge(other ScalarTypes) ScalarTypes {
+	merged := make(ScalarTypes, len(st)+len(other))
+	for t, name := range st {
+		merged[t] = name
+	}
+	for t, name := range other {
+		merged[t] = name
+	}
+	return merged
+}
+
+// scalarTypeLayers are the registries of an operation, e.g. of the client then of
+// the operation. They are looked up from the last one, so that it takes precedence,
+// without merging them on every operation.
+type scalarTypeLayers []ScalarTypes
+
+// lookup returns the scalar name of t, if t is registered in any layer.
+func (l scalarTypeLayers) lookup(t reflect.Type) (string, bool) {
+	for i := len(l) - 1; i >= 0; i-- {
+		if name, ok := l[i].lookup(t); ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// scalarTypesOption represents the scalar types option
+type scalarTypesOption struct {
+	types ScalarTypes
+}
+
+func (sto scalarTypesOption) Type() OptionType {
+	return optionTypeScalarTypes
+}
+
+// String returns an empty string because the option isn't rendered into the query
+func (sto scalarTypesOption) String() string {
+	return ""
+}
+
+// Scalars creates the option that registers scalar types for a single operation.
+// They are merged with the scalar types of the client, taking precedence over them.
+// The types are copied, so later changes of types don't affect the option.
+func Scalars(types ScalarTypes) Option {
+	return scalarTypesOption{ScalarTypes(nil).merge(types)}
+}
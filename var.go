@@ -0,0 +1,390 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Var is a variable with an explicit definition. Use it as a value of the
+// variables map when the definition can't be derived from the Go value,
+// e.g. to declare a non-null Go value as nullable, to name an input type
+// without a wrapper type, or to set a default value.
+//
+//	variables := map[string]interface{}{
+//		"first": graphql.Var{Value: 20, Type: "Int", Default: 10},
+//		"after": graphql.Var{Type: "String"},
+//	}
+//	// query ($after:String$first:Int=10){...}
+//
+// A Var with a nil Value is omitted from the variables of the request,
+// so that the server uses the default value. Use a typed nil pointer,
+// e.g. (*int)(nil), to send an explicit null instead.
+type Var struct {
+	// Value is sent as the value of the variable.
+	Value interface{}
+	// Type is the GraphQL type of the variable, e.g. "Int" or "[ID!]!".
+	// If empty, it's derived from Value, or from Default if Value is nil.
+	Type string
+	// Default is the default value of the variable, if not nil.
+	// It's written into the query as a GraphQL literal. Enum values are written
+	// unquoted and struct fields are named as in variables. A null default value
+	// makes the derived type nullable.
+	Default interface{}
+	// Directives are written after the default value, e.g. "@deprecated".
+	Directives []string
+}
+
+// MarshalJSON encodes the value of the variable.
+func (v Var) MarshalJSON() ([]byte, error) {
+	return marshalInput(reflect.ValueOf(v.Value))
+}
+
+// asVar returns the Var of value, if value is a Var or a non-nil *Var.
+func asVar(value interface{}) (Var, bool) {
+	switch v := value.(type) {
+	case Var:
+		return v, true
+	case *Var:
+		if v != nil {
+			return *v, true
+		}
+	}
+	return Var{}, false
+}
+
+// VariablesFrom returns the variables map of the exported fields of struct v,
+// so that variables can be declared with a struct instead of a map.
+// v may be a pointer to struct.
+//
+// Variables are named by the graphql tag of the fields, or by the field names
+// in lowerCamelCase. Fields tagged with graphql:"-" are skipped. The type tag
+// overrides the GraphQL type of a variable, like Var.Type. Fields of type Var
+// are used as is.
+//
+//	variables, err := graphql.VariablesFrom(struct {
+//		Login string `graphql:"login"`
+//		First int    `type:"Int"`
+//	}{Login: "gopher", First: 10})
+//	// query ($first:Int$login:String!){...}
+func VariablesFrom(v interface{}) (map[string]interface{}, error) {
+	return variablesFrom(v, LowerCamelCaseNaming)
+}
+
+// variablesFrom returns the variables map of struct v, naming untagged fields with naming.
+func variablesFrom(v interface{}, naming NamingStrategy) (map[string]interface{}, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("variables must be a non-nil pointer to struct, got %T", v)
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("variables must be a struct, got %T", v)
+	}
+
+	variables := make(map[string]interface{}, rv.NumField())
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Type().Field(i)
+		if f.PkgPath != "" {
+			// Skip unexported field.
+			continue
+		}
+		name := f.Tag.Get("graphql")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = naming(f.Name)
+		}
+		if _, ok := variables[name]; ok {
+			return nil, fmt.Errorf("duplicate variable $%s", name)
+		}
+		value := rv.Field(i).Interface()
+		if typ := f.Tag.Get("type"); typ != "" {
+			if v, ok := asVar(value); ok {
+				v.Type = typ
+				value = v
+			} else {
+				value = Var{Value: value, Type: typ}
+			}
+		}
+		variables[name] = value
+	}
+	return variables, nil
+}
+
+// variablesOption represents the variables of a struct
+type variablesOption struct {
+	v interface{}
+}
+
+func (vo variablesOption) Type() OptionType {
+	return optionTypeVariables
+}
+
+// String returns an empty string because the option isn't rendered into the query
+func (vo variablesOption) String() string {
+	return ""
+}
+
+// Variables creates the option that declares variables with struct v, as VariablesFrom does,
+// except that untagged fields are named by the naming strategy of the operation, like its fields.
+// They are added to the variables map of the operation, which may be nil.
+//
+//	err := client.Query(ctx, &q, nil, graphql.Variables(struct {
+//		Login string `graphql:"login"`
+//	}{Login: "gopher"}))
+func Variables(v interface{}) Option {
+	return variablesOption{v}
+}
+
+// withOptionVariables returns the variables with the variables of the Variables options,
+// and the options without them. variables and options are returned as is if there are none.
+// Untagged fields are named by the FieldNaming options, or else by naming if not nil.
+func withOptionVariables(variables map[string]interface{}, options []Option, naming NamingStrategy) (map[string]interface{}, []Option, error) {
+	for _, option := range options {
+		if o, ok := option.(fieldNamingOption); ok {
+			naming = o.strategy
+		}
+	}
+	if naming == nil {
+		naming = LowerCamelCaseNaming
+	}
+	var merged map[string]interface{}
+	var rest []Option
+	for i, option := range options {
+		vo, ok := option.(variablesOption)
+		if !ok {
+			if rest != nil {
+				rest = append(rest, option)
+			}
+			continue
+		}
+		if rest == nil {
+			rest = append(make([]Option, 0, len(options)), options[:i]...)
+			merged = make(map[string]interface{}, len(variables))
+			for name, value := range variables {
+				merged[name] = value
+			}
+		}
+		fromStruct, err := variablesFrom(vo.v, naming)
+		if err != nil {
+			return nil, nil, err
+		}
+		for name, value := range fromStruct {
+			if _, ok := merged[name]; ok {
+				return nil, nil, fmt.Errorf("duplicate variable $%s", name)
+			}
+			merged[name] = value
+		}
+	}
+	if rest == nil {
+		return variables, options, nil
+	}
+	return merged, rest, nil
+}
+
+// writeVarDefinition writes the minified definition of v, without the variable name, to w.
+// A derived type is nullable if the default value is null, which non-null types can't have.
+//
+// E.g., Var{Value: 20, Type: "Int", Default: 10} -> "Int=10".
+func writeVarDefinition(w io.Writer, v Var, options *constructOptionsOutput) error {
+	var def bytes.Buffer
+	if v.Default != nil {
+		if err := writeLiteral(&def, reflect.ValueOf(v.Default)); err != nil {
+			return fmt.Errorf("invalid default value: %w", err)
+		}
+	}
+	nullDefault := def.String() == "null"
+
+	var typ bytes.Buffer
+	switch {
+	case v.Type != "":
+		if nullDefault && strings.HasSuffix(v.Type, "!") {
+			return fmt.Errorf("non-null type %s can't have a null default value", v.Type)
+		}
+		typ.WriteString(v.Type)
+	case v.Value != nil:
+		if err := writeVariableType(&typ, v.Value, options); err != nil {
+			return err
+		}
+	case v.Default != nil:
+		if err := writeVariableType(&typ, v.Default, options); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("type can't be derived from a nil value and default value")
+	}
+	if nullDefault {
+		typ.Truncate(len(strings.TrimSuffix(typ.String(), "!")))
+	}
+	typ.WriteTo(w)
+	if v.Default != nil {
+		io.WriteString(w, "=")
+		def.WriteTo(w)
+	}
+	for _, directive := range v.Directives {
+		io.WriteString(w, directive)
+	}
+	return nil
+}
+
+// writeVariableType writes the minified GraphQL type of the variable value to w.
+// Omittable values are nullable, with the type of their value.
+func writeVariableType(w io.Writer, value interface{}, options *constructOptionsOutput) error {
+	if o, ok := asOmittable(value); ok {
+		t := o.graphQLType()
+		if t == nil {
+			return fmt.Errorf("type can't be derived from an omitted or null value, use Present with a typed value, NullOf, OmittedOf or Var")
+		}
+		writeArgumentType(w, t, false, options)
+		return nil
+	}
+	writeArgumentType(w, reflect.TypeOf(value), true, options)
+	return nil
+}
+
+// writeLiteral writes v to w as a minified GraphQL input value literal.
+//
+// E.g., map[string]interface{}{"first": 10, "orderBy": "NAME"} -> `{first:10,orderBy:"NAME"}`.
+func writeLiteral(w io.Writer, v reflect.Value) error {
+	if !v.IsValid() {
+		io.WriteString(w, "null")
+		return nil
+	}
+	t := v.Type()
+	if t == variableType {
+		io.WriteString(w, "$")
+		io.WriteString(w, v.String())
+		return nil
+	}
+	if t.Kind() == reflect.String && t.Implements(graphqlEnumInterface) {
+		io.WriteString(w, v.String())
+		return nil
+	}
+	if t == omittableType {
+		return writeLiteral(w, reflect.ValueOf(v.Interface().(Omittable).value))
+	}
+	if t == jsonNumberType {
+		io.WriteString(w, v.String())
+		return nil
+	}
+	if t.Implements(jsonMarshaler) && (t.Kind() != reflect.Ptr || !v.IsNil()) {
+		return writeJSONLiteral(w, v.Interface())
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			io.WriteString(w, "null")
+			return nil
+		}
+		return writeLiteral(w, v.Elem())
+	case reflect.Bool:
+		io.WriteString(w, strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		io.WriteString(w, strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		io.WriteString(w, strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return fmt.Errorf("unsupported float value %v", f)
+		}
+		io.WriteString(w, strconv.FormatFloat(f, 'g', -1, t.Bits()))
+	case reflect.String:
+		// JSON string escapes are valid in GraphQL strings.
+		b, err := json.Marshal(v.String())
+		if err != nil {
+			return err
+		}
+		w.Write(b)
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && v.IsNil() {
+			io.WriteString(w, "null")
+			return nil
+		}
+		io.WriteString(w, "[")
+		for i := 0; i < v.Len(); i++ {
+			if i != 0 {
+				io.WriteString(w, ",")
+			}
+			if err := writeLiteral(w, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		io.WriteString(w, "]")
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported map key type %v", t.Key())
+		}
+		if v.IsNil() {
+			io.WriteString(w, "null")
+			return nil
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		io.WriteString(w, "{")
+		for i, key := range keys {
+			if i != 0 {
+				io.WriteString(w, ",")
+			}
+			io.WriteString(w, key.String())
+			io.WriteString(w, ":")
+			if err := writeLiteral(w, v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+		io.WriteString(w, "}")
+	case reflect.Struct:
+		// Fields are written as literals, rather than by their JSON encoding, so that enum values are unquoted.
+		io.WriteString(w, "{")
+		more := false
+		for _, f := range inputFields(t) {
+			fv, ok := fieldByIndex(v, f.index)
+			if !ok || (f.omitEmpty && isEmptyValue(fv)) || isOmitted(fv) {
+				continue
+			}
+			if more {
+				io.WriteString(w, ",")
+			}
+			io.WriteString(w, f.name)
+			io.WriteString(w, ":")
+			if err := writeLiteral(w, fv); err != nil {
+				return fmt.Errorf("field %s: %w", f.name, err)
+			}
+			more = true
+		}
+		io.WriteString(w, "}")
+	default:
+		return fmt.Errorf("unsupported type %v", t)
+	}
+	return nil
+}
+
+// writeJSONLiteral writes the JSON encoding of v to w as a GraphQL literal.
+func writeJSONLiteral(w io.Writer, v interface{}) error {
+	b, err := marshalInput(reflect.ValueOf(v))
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return err
+	}
+	return writeLiteral(w, reflect.ValueOf(value))
+}
+
+var jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+var jsonNumberType = reflect.TypeOf(json.Number(""))
+var variableType = reflect.TypeOf(Variable(""))